@@ -59,3 +59,29 @@ func do[T any](req *http.Request, token string, userId string) (result T, code i
 	}
 	return
 }
+
+// doDecodeStatus is like do, but also decodes the body of error responses with the given status code. The error is returned nevertheless.
+func doDecodeStatus[T any](req *http.Request, token string, userId string, status int) (result T, code int, err error) {
+	req.Header.Set("Authorization", token)
+	req.Header.Set("X-UserId", userId)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+	defer resp.Body.Close()
+	temp, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+	if resp.StatusCode > 299 && resp.StatusCode != status {
+		return result, resp.StatusCode, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp))
+	}
+	err = json.Unmarshal(temp, &result)
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+	if resp.StatusCode > 299 {
+		return result, resp.StatusCode, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp))
+	}
+	return result, resp.StatusCode, nil
+}
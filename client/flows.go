@@ -20,7 +20,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
 )
@@ -35,6 +38,46 @@ func (c *Client) GetFlows(token, userId string) (resp lib.FlowsResponse, code in
 	return do[lib.FlowsResponse](req, token, userId)
 }
 
+func (c *Client) GetFlowsPage(token, userId string, args url.Values) (resp lib.FlowsResponse, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"?"+args.Encode(), nil)
+	if err != nil {
+		return resp, http.StatusBadRequest, err
+	}
+	return do[lib.FlowsResponse](req, token, userId)
+}
+
+// GetAllFlows requests all flows matching args page by page using cursor pagination.
+func (c *Client) GetAllFlows(token, userId string, args url.Values, pageSize int) (flows []lib.Flow, code int, err error) {
+	pageArgs := maps.Clone(args)
+	if pageArgs == nil {
+		pageArgs = url.Values{}
+	}
+	pageArgs.Set("limit", strconv.Itoa(pageSize))
+	pageArgs.Set("cursor", "")
+	pageArgs.Del("offset")
+	flows = []lib.Flow{}
+	for {
+		var resp lib.FlowsResponse
+		resp, code, err = c.GetFlowsPage(token, userId, pageArgs)
+		if err != nil {
+			return nil, code, err
+		}
+		flows = append(flows, resp.Flows...)
+		if resp.NextCursor == "" {
+			return
+		}
+		pageArgs.Set("cursor", resp.NextCursor)
+	}
+}
+
+func (c *Client) GetTags(token, userId string) (tags []lib.TagCount, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/tags", nil)
+	if err != nil {
+		return tags, http.StatusBadRequest, err
+	}
+	return do[[]lib.TagCount](req, token, userId)
+}
+
 func (c *Client) GetFlow(token, userId, id string) (flow lib.Flow, code int, err error) {
 	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/"+id, nil)
 	if err != nil {
@@ -43,6 +86,39 @@ func (c *Client) GetFlow(token, userId, id string) (flow lib.Flow, code int, err
 	return do[lib.Flow](req, token, userId)
 }
 
+func (c *Client) GetFlowAnalysis(token, userId, id string) (analysis lib.FlowAnalysis, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/"+id+"/analysis", nil)
+	if err != nil {
+		return analysis, http.StatusBadRequest, err
+	}
+	return do[lib.FlowAnalysis](req, token, userId)
+}
+
+func (c *Client) GetFlowRevisions(token, userId, id string) (resp lib.FlowRevisionsResponse, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/"+id+"/revisions", nil)
+	if err != nil {
+		return resp, http.StatusBadRequest, err
+	}
+	return do[lib.FlowRevisionsResponse](req, token, userId)
+}
+
+func (c *Client) GetFlowRevision(token, userId, id string, revision int64) (flowRevision lib.FlowRevision, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/"+id+"/revisions/"+strconv.FormatInt(revision, 10), nil)
+	if err != nil {
+		return flowRevision, http.StatusBadRequest, err
+	}
+	return do[lib.FlowRevision](req, token, userId)
+}
+
+func (c *Client) RestoreFlowRevision(token, userId, id string, revision int64) (code int, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+FlowPath+"/"+id+"/revisions/"+strconv.FormatInt(revision, 10)+"/restore", nil)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	_, code, err = doNoDecode(req, token, userId)
+	return code, err
+}
+
 func (c *Client) CreateFlow(token string, userId string, flow lib.Flow) (created lib.FlowCreateResponse, code int, err error) {
 	b, err := json.Marshal(flow)
 	if err != nil {
@@ -71,6 +147,83 @@ func (c *Client) UpdateFlow(token string, userId string, flow lib.Flow) (code in
 	return code, err
 }
 
+// PatchFlow sends a patch document to the flow repo. The content type has to be lib.MergePatchContentType or lib.JSONPatchContentType.
+func (c *Client) PatchFlow(token, userId, id string, contentType string, patch []byte) (code int, err error) {
+	req, err := http.NewRequest(http.MethodPatch, c.baseUrl+FlowPath+"/"+id, bytes.NewBuffer(patch))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	_, code, err = doNoDecode(req, token, userId)
+	return code, err
+}
+
+func (c *Client) CloneFlow(token, userId, id string) (created lib.FlowCreateResponse, code int, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+FlowPath+"/"+id+"/clone", nil)
+	if err != nil {
+		return created, http.StatusBadRequest, err
+	}
+	return do[lib.FlowCreateResponse](req, token, userId)
+}
+
+func (c *Client) ExportFlow(token, userId, id string) (bundle lib.FlowExport, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/"+id+"/export", nil)
+	if err != nil {
+		return bundle, http.StatusBadRequest, err
+	}
+	return do[lib.FlowExport](req, token, userId)
+}
+
+// ImportFlow imports an exported flow. If operators could not be resolved, code is 422 and resp lists the unresolved operators.
+func (c *Client) ImportFlow(token, userId string, bundle lib.FlowExport) (resp lib.FlowImportResponse, code int, err error) {
+	b, err := json.Marshal(bundle)
+	if err != nil {
+		return resp, http.StatusBadRequest, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+FlowPath+"/import", bytes.NewBuffer(b))
+	if err != nil {
+		return resp, http.StatusBadRequest, err
+	}
+	return doDecodeStatus[lib.FlowImportResponse](req, token, userId, http.StatusUnprocessableEntity)
+}
+
+func (c *Client) GetFlowPermissions(token, userId, id string) (permissions lib.FlowPermissions, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/"+id+"/permissions", nil)
+	if err != nil {
+		return permissions, http.StatusBadRequest, err
+	}
+	return do[lib.FlowPermissions](req, token, userId)
+}
+
+func (c *Client) SetFlowPermissions(token, userId, id string, permissions lib.FlowPermissions) (result lib.FlowPermissions, code int, err error) {
+	b, err := json.Marshal(permissions)
+	if err != nil {
+		return result, http.StatusBadRequest, err
+	}
+	req, err := http.NewRequest(http.MethodPut, c.baseUrl+FlowPath+"/"+id+"/permissions", bytes.NewBuffer(b))
+	if err != nil {
+		return result, http.StatusBadRequest, err
+	}
+	return do[lib.FlowPermissions](req, token, userId)
+}
+
+func (c *Client) GetDeletedFlows(token, userId string) (resp lib.FlowsResponse, code int, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseUrl+FlowPath+"/trash", nil)
+	if err != nil {
+		return resp, http.StatusBadRequest, err
+	}
+	return do[lib.FlowsResponse](req, token, userId)
+}
+
+func (c *Client) RestoreFlow(token, userId, id string) (code int, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl+FlowPath+"/"+id+"/restore", nil)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	_, code, err = doNoDecode(req, token, userId)
+	return code, err
+}
+
 func (c *Client) DeleteFlow(token, userId, id string) (code int, err error) {
 	req, err := http.NewRequest(http.MethodDelete, c.baseUrl+FlowPath+"/"+id+"/", nil)
 	if err != nil {
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+func TestImportFlow(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		body       any
+		unresolved int
+		err        bool
+	}{
+		{"created", http.StatusCreated, lib.FlowImportResponse{Id: "a"}, 0, false},
+		{"unresolved operators", http.StatusUnprocessableEntity, lib.FlowImportResponse{UnresolvedOperators: []lib.UnresolvedOperator{
+			{FlowExportOperator: lib.FlowExportOperator{Id: "op"}, Reason: "no operator with matching name"},
+		}}, 1, true},
+		{"bad request", http.StatusBadRequest, "invalid flow model", 0, true},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != FlowPath+"/import" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(test.code)
+			_ = json.NewEncoder(w).Encode(test.body)
+		}))
+		resp, code, err := NewClient(server.URL).ImportFlow("token", "user", lib.FlowExport{})
+		server.Close()
+		if code != test.code || (err != nil) != test.err || len(resp.UnresolvedOperators) != test.unresolved {
+			t.Fatalf("%s: unexpected response %+v with code %d and error %v", test.name, resp, code, err)
+		}
+		if test.unresolved > 0 && resp.UnresolvedOperators[0].Id != "op" {
+			t.Fatalf("%s: unexpected unresolved operators %+v", test.name, resp.UnresolvedOperators)
+		}
+	}
+}
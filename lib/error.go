@@ -16,7 +16,12 @@
 
 package lib
 
-import "github.com/SENERGY-Platform/analytics-pipeline/lib"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-pipeline/lib"
+)
 
 type cError struct {
 	err error
@@ -47,6 +52,24 @@ type ForbiddenError struct {
 	cError
 }
 
+// ModelError lists the problems of an invalid flow model. It is always wrapped in an InputError.
+type ModelError struct {
+	msg      string
+	Problems []ModelProblem
+}
+
+func (e *ModelError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, fmt.Sprintf("cell '%s': %s", p.CellId, p.Message))
+	}
+	return e.msg + ": " + strings.Join(msgs, "; ")
+}
+
+type PreconditionFailedError struct {
+	cError
+}
+
 func (e *cError) Error() string {
 	return e.err.Error()
 }
@@ -63,6 +86,10 @@ func NewInputError(err error) error {
 	return &InputError{cError{err: err}}
 }
 
+func NewModelError(msg string, problems []ModelProblem) error {
+	return NewInputError(&ModelError{msg: msg, Problems: problems})
+}
+
 func NewNotFoundError(err error) error {
 	return &NotFoundError{cError{err: err}}
 }
@@ -78,3 +105,7 @@ func NewExternalResourceError(err error) error {
 func NewForbiddenError(err error) error {
 	return &ForbiddenError{cError{err: err}}
 }
+
+func NewPreconditionFailedError(err error) error {
+	return &PreconditionFailedError{cError{err: err}}
+}
@@ -19,22 +19,71 @@ package lib
 import (
 	"time"
 
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const FlowExportVersion = 1
+
+const FlowEventVersion = 1
+
+type FlowEventType string
+
+const (
+	FlowCreated  FlowEventType = "created"
+	FlowUpdated  FlowEventType = "updated"
+	FlowDeleted  FlowEventType = "deleted"
+	FlowRestored FlowEventType = "restored"
+)
+
+// codes of model problems
+const (
+	ProblemMissingId         = "missing_id"
+	ProblemDuplicateId       = "duplicate_id"
+	ProblemMissingOperatorId = "missing_operator_id"
+	ProblemMissingSource     = "missing_source"
+	ProblemMissingTarget     = "missing_target"
+	ProblemUnknownNode       = "unknown_node"
+	ProblemUnknownPort       = "unknown_port"
+	ProblemCycle             = "cycle"
+	ProblemUnreachableNode   = "unreachable_node"
+	ProblemUnusedInputPort   = "unused_input_port"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
 type FlowsResponse struct {
-	Flows []Flow `json:"flows"`
-	Total int64  `json:"total"`
+	Flows      []Flow `json:"flows"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 type Flow struct {
 	Id          *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Name        string              `json:"name,omitempty"`
 	Description *string             `json:"description,omitempty"`
-	Model       Model               `json:"model,omitempty"`
+	Model       Model               `json:"model,omitzero"`
 	Image       *string             `json:"image,omitempty"`
 	UserId      string              `bson:"userId,omitempty" json:"userId,omitempty"`
-	DateCreated time.Time           `bson:"dateCreated,omitempty" json:"dateCreated,omitempty"`
-	DateUpdated time.Time           `bson:"dateUpdated,omitempty" json:"dateUpdated,omitempty"`
+	DateCreated time.Time           `bson:"dateCreated,omitempty" json:"dateCreated,omitzero"`
+	DateUpdated time.Time           `bson:"dateUpdated,omitempty" json:"dateUpdated,omitzero"`
+	Version     int64               `bson:"version" json:"version"`
+	DateDeleted *time.Time          `bson:"dateDeleted,omitempty" json:"dateDeleted,omitempty" readonly:"true"` // only set by moving a flow to the trash
+	Tags        []string            `bson:"tags,omitempty" json:"tags,omitempty"`
+	Summary     *FlowSummary        `bson:"summary,omitempty" json:"summary,omitempty"`
+	Score       *float64            `bson:"score,omitempty" json:"score,omitempty"`
+}
+
+type FlowSummary struct {
+	Nodes int `bson:"nodes" json:"nodes"`
+	Links int `bson:"links" json:"links"`
+}
+
+type TagCount struct {
+	Tag   string `bson:"_id" json:"tag"`
+	Count int64  `bson:"count" json:"count"`
 }
 
 type FlowCreateResponse struct {
@@ -47,18 +96,18 @@ type Model struct {
 
 type Cell struct {
 	Type           string         `json:"type,omitempty"`
-	InPorts        []string       `json:"inPorts,omitempty"`
-	OutPorts       []string       `json:"outPorts,omitempty"`
+	InPorts        []string       `bson:"inPorts" json:"inPorts,omitempty"`
+	OutPorts       []string       `bson:"outPorts" json:"outPorts,omitempty"`
 	Name           *string        `json:"name,omitempty"`
 	Image          *string        `json:"image,omitempty"`
-	OperatorId     *string        `json:"operatorId,omitempty"`
+	OperatorId     *string        `bson:"operatorId" json:"operatorId,omitempty"`
 	Position       *CellPosition  `json:"position,omitempty"`
 	Source         *CellLink      `json:"source,omitempty"`
 	Target         *CellLink      `json:"target,omitempty"`
 	Id             string         `json:"id,omitempty"`
 	Config         *[]ConfigValue `json:"config,omitempty"`
 	Cost           *int64         `json:"cost,omitempty"`
-	DeploymentType *string        `json:"deploymentType,omitempty"`
+	DeploymentType *string        `bson:"deploymentType" json:"deploymentType,omitempty"`
 	Version        *int           `json:"version,omitempty"`
 }
 
@@ -87,3 +136,127 @@ type FlowCount struct {
 	FlowID *primitive.ObjectID `bson:"flowId"`
 	Count  int32               `bson:"count"`
 }
+
+type OperatorCacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+}
+
+// ReadinessReport is the result of checking all dependencies. The service is ready if no required dependency is down.
+type ReadinessReport struct {
+	Ready        bool               `json:"ready"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Up        bool   `json:"up"`
+	Optional  bool   `json:"optional"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type FlowAnalysis struct {
+	TopologicalOrder []string   `json:"topologicalOrder"`
+	Sources          []string   `json:"sources"`
+	Sinks            []string   `json:"sinks"`
+	Cycles           [][]string `json:"cycles"`
+	UnreachableNodes []string   `json:"unreachableNodes"`
+	UnusedInputPorts []NodePort `json:"unusedInputPorts"`
+	// Problems lists cycles, unreachable nodes and unused input ports per cell
+	Problems []ModelProblem `json:"problems"`
+}
+
+// ModelProblem describes a problem of a model cell. Port is only set if the problem concerns a single port.
+type ModelProblem struct {
+	CellId  string `json:"cellId"`
+	Port    string `json:"port,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ModelErrorResponse struct {
+	Error    string         `json:"error"`
+	Problems []ModelProblem `json:"problems"`
+}
+
+type NodePort struct {
+	NodeId string `json:"nodeId"`
+	Port   string `json:"port"`
+}
+
+type FlowRevisionsResponse struct {
+	Revisions []FlowRevision `json:"revisions"`
+	Total     int64          `json:"total"`
+}
+
+type FlowRevision struct {
+	FlowId      string    `bson:"flowId" json:"flowId"`
+	Revision    int64     `bson:"revision" json:"revision"`
+	Name        string    `bson:"name" json:"name"`
+	Description *string   `bson:"description,omitempty" json:"description,omitempty"`
+	Model       *Model    `bson:"model,omitempty" json:"model,omitempty"`
+	Author      string    `bson:"author" json:"author"`
+	Timestamp   time.Time `bson:"timestamp" json:"timestamp"`
+}
+
+// FlowEvent notifies other services about a changed flow. Events are delivered at least once,
+// consumers can use the id to detect duplicates.
+type FlowEvent struct {
+	Id       string        `bson:"_id" json:"id"`
+	Version  int           `bson:"version" json:"version"`
+	Type     FlowEventType `bson:"type" json:"type"`
+	FlowId   string        `bson:"flowId" json:"flowId"`
+	UserId   string        `bson:"userId" json:"userId"`
+	Revision int64         `bson:"revision,omitempty" json:"revision,omitempty"`
+	Time     time.Time     `bson:"time" json:"time"`
+}
+
+// FlowExport is a portable representation of a flow. It contains no installation specific ids except the
+// operator ids of the source system, which are resolved by name and image on import.
+type FlowExport struct {
+	Version     int                  `json:"version"`
+	Name        string               `json:"name"`
+	Description *string              `json:"description,omitempty"`
+	Image       *string              `json:"image,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Model       Model                `json:"model"`
+	Operators   []FlowExportOperator `json:"operators"`
+	DateExport  time.Time            `json:"dateExport"`
+}
+
+type FlowExportOperator struct {
+	Id             string                `json:"id"`
+	Name           string                `json:"name"`
+	Image          string                `json:"image"`
+	Description    string                `json:"description,omitempty"`
+	DeploymentType string                `json:"deploymentType,omitempty"`
+	Config         []operator_repo.Value `json:"config_values,omitempty"`
+	Inputs         []operator_repo.Value `json:"inputs,omitempty"`
+	Outputs        []operator_repo.Value `json:"outputs,omitempty"`
+}
+
+type FlowImportResponse struct {
+	Id                  string               `json:"_id,omitempty"`
+	OperatorMapping     map[string]string    `json:"operatorMapping"`
+	UnresolvedOperators []UnresolvedOperator `json:"unresolvedOperators"`
+}
+
+type UnresolvedOperator struct {
+	FlowExportOperator
+	Reason string `json:"reason"`
+}
+
+type FlowPermissions struct {
+	UserPermissions  map[string]PermissionsMap `json:"user_permissions"`
+	GroupPermissions map[string]PermissionsMap `json:"group_permissions"`
+	RolePermissions  map[string]PermissionsMap `json:"role_permissions"`
+}
+
+type PermissionsMap struct {
+	Read         bool `json:"read"`
+	Write        bool `json:"write"`
+	Execute      bool `json:"execute"`
+	Administrate bool `json:"administrate"`
+}
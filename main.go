@@ -25,6 +25,7 @@ import (
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/api"
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/events"
 	operator_api "github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/operator-api"
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/repo"
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
@@ -56,19 +57,11 @@ func main() {
 	util.InitStructLogger(cfg.Logger.Level)
 
 	util.Logger.Info(srvInfoHdl.Name(), "version", srvInfoHdl.Version())
-	util.Logger.Info("config: " + sb_util.ToJsonStr(cfg))
-
-	err = repo.InitDB(cfg.MongoUrl)
-	if err != nil {
-		util.Logger.Error("error on db init", "error", err)
-		ec = 1
-		return
-	}
-	util.Logger.Debug("connected to database")
-	defer repo.CloseDB()
+	util.Logger.Info("config: " + sb_util.ToJsonStr(cfg.Redacted()))
 
 	ctx, cf := context.WithCancel(context.Background())
 	defer cf()
+
 	var perm permV2Client.Client
 
 	if cfg.PermissionsV2Url == "mock" {
@@ -78,17 +71,105 @@ func main() {
 		perm = permV2Client.New(cfg.PermissionsV2Url)
 	}
 
+	var dbRepo repo.FlowRepository
+
+	// the migration and index flags run only the requested database maintenance and exit
+	maintenance := util.Flags.Migrate || util.Flags.MigrateDryRun || util.Flags.EnsureIndexes
+
+	if cfg.MongoUrl == "memory" {
+		if maintenance {
+			util.Logger.Error("migrations and indexes require mongodb, the in-memory database has none")
+			ec = 1
+			return
+		}
+		util.Logger.Debug("using in-memory database")
+		dbRepo, err = repo.NewMemoryRepo(cfg, perm)
+		if err != nil {
+			util.Logger.Error("error on new db repo", "error", err)
+			ec = 1
+			return
+		}
+	} else {
+		mongoClient, err := repo.NewMongoClient(ctx, cfg.MongoUrl)
+		if err != nil {
+			util.Logger.Error("error on db init", "error", err)
+			ec = 1
+			return
+		}
+		util.Logger.Debug("connected to database")
+		defer func() {
+			if err := mongoClient.Disconnect(context.Background()); err != nil {
+				util.Logger.Error("error on db disconnect", "error", err)
+			}
+		}()
+
+		mongoRepo, err := repo.NewMongoRepo(ctx, cfg, mongoClient, perm)
+		if err != nil {
+			util.Logger.Error("error on new db repo", "error", err)
+			ec = 1
+			return
+		}
+
+		if !maintenance || util.Flags.Migrate || util.Flags.MigrateDryRun {
+			err = mongoRepo.Migrate(ctx, util.Flags.MigrateDryRun)
+			if err != nil {
+				util.Logger.Error("error on migration", "error", err)
+				ec = 1
+				return
+			}
+			if maintenance {
+				util.Logger.Info("migrations done")
+			}
+		}
+
+		if !maintenance || util.Flags.EnsureIndexes {
+			err = mongoRepo.EnsureIndexes(ctx)
+			if err != nil {
+				util.Logger.Error("error on index creation", "error", err)
+				ec = 1
+				return
+			}
+			if maintenance {
+				util.Logger.Info("indexes ensured")
+			}
+		}
+		if maintenance {
+			return
+		}
+
+		err = mongoRepo.ValidateFlowPermissions(ctx)
+		if err != nil {
+			util.Logger.Error("error on flow permission validation", "error", err)
+			ec = 1
+			return
+		}
+		dbRepo = mongoRepo
+	}
+
 	var pipe pipelinesClient.Client
 	pipe = *pipelinesClient.NewClient(cfg.PipelineRegistryUrl)
 
-	operatorRepo := operator_api.New(cfg.OperatorRepoUrl)
-	srv, err := repo.New(*srvInfoHdl, perm, operatorRepo, pipe)
-	if err != nil {
-		util.Logger.Error("error on new repo", "error", err)
-		ec = 1
-		return
+	operatorRepo := operator_api.New(cfg.OperatorRepoUrl, cfg.HttpTimeout, cfg.OperatorCacheTTL)
+	var publisher events.Publisher
+
+	switch cfg.KafkaUrl {
+	case "":
+		util.Logger.Info("flow events disabled")
+	case "memory":
+		util.Logger.Debug("using in-memory event broker")
+		publisher = events.NewMemoryBroker()
+	default:
+		kafkaPublisher := events.NewKafkaPublisher(cfg.KafkaUrl, cfg.FlowEventsTopic, cfg.HttpTimeout)
+		defer func() {
+			if err := kafkaPublisher.Close(); err != nil {
+				util.Logger.Error("error on kafka close", "error", err)
+			}
+		}()
+		publisher = kafkaPublisher
 	}
 
+	srv := repo.New(cfg, *srvInfoHdl, dbRepo, operatorRepo, pipe, publisher)
+
 	httpHandler, err := api.New(srv, map[string]string{
 		api.HeaderApiVer:  srvInfoHdl.Version(),
 		api.HeaderSrvName: srvInfoHdl.Name(),
@@ -118,6 +199,24 @@ func main() {
 		cf()
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		srv.RunTrashPurge(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		srv.RunFlowMetrics(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		srv.RunEventRelay(ctx)
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
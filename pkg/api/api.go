@@ -21,7 +21,9 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
 	gin_mw "github.com/SENERGY-Platform/gin-middleware"
 	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
@@ -44,18 +46,19 @@ func New(srv Repo, staticHeader map[string]string, urlPrefix string) (*gin.Engin
 	httpHandler.RedirectTrailingSlash = false
 	httpHandler.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT", "PATCH"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", HeaderIfMatch},
+		ExposeHeaders:    []string{"Content-Length", HeaderETag},
 		AllowCredentials: true,
 	}))
 	var middleware []gin.HandlerFunc
 	middleware = append(
 		middleware,
+		MetricsMiddleware(),
 		gin_mw.StructLoggerHandlerWithDefaultGenerators(
 			util.Logger.With(attributes.LogRecordTypeKey, attributes.HttpAccessLogRecordTypeVal),
 			attributes.Provider,
-			[]string{HealthCheckPath},
+			[]string{HealthCheckPath, ReadinessCheckPath, MetricsPath},
 			nil,
 		),
 	)
@@ -63,6 +66,7 @@ func New(srv Repo, staticHeader map[string]string, urlPrefix string) (*gin.Engin
 		gin_mw.StaticHeaderHandler(staticHeader),
 		requestid.New(requestid.WithCustomHeaderStrKey(HeaderRequestID)),
 		gin_mw.ErrorHandler(GetStatusCode, ", "),
+		ModelErrorHandler(),
 		gin_mw.StructRecoveryHandler(util.Logger, gin_mw.DefaultRecoveryFunc),
 	)
 	httpHandler.Use(middleware...)
@@ -88,12 +92,31 @@ func New(srv Repo, staticHeader map[string]string, urlPrefix string) (*gin.Engin
 	if err != nil {
 		return nil, err
 	}
+	// gin_mw joins the paths with path.Join, which drops trailing slashes, so the labels are taken from gin, like gc.FullPath
+	routeLabels := make([][2]string, 0, len(allRoutes))
+	for _, route := range httpHandler.Routes() {
+		routeLabels = append(routeLabels, [2]string{route.Method, route.Path})
+	}
+	metrics.InitHttpRoutes(routeLabels)
 	for _, route := range allRoutes {
 		util.Logger.Debug("http route", attributes.MethodKey, route[0], attributes.PathKey, route[1])
 	}
 	return httpHandler, nil
 }
 
+// MetricsMiddleware records the duration and status code of every request, labeled by the registered route.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		start := time.Now()
+		gc.Next()
+		route := gc.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHttpRequest(gc.Request.Method, route, gc.Writer.Status(), time.Since(start))
+	}
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(gc *gin.Context) {
 		userId, err := getUserId(gc)
@@ -0,0 +1,291 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	operator_api "github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/operator-api"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/repo"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
+	pipelinesClient "github.com/SENERGY-Platform/analytics-pipeline/client"
+	srv_info_hdl "github.com/SENERGY-Platform/go-service-base/srv-info-hdl"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	util.InitStructLogger("error")
+	os.Exit(m.Run())
+}
+
+// newTestServer serves the api on a MemoryRepo with mocked permissions and without operator repository and pipeline registry.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newTestServerWithConfig(t, func(*config.Config) {})
+}
+
+// newTestServerWithConfig is newTestServer with a config changed by configure.
+func newTestServerWithConfig(t *testing.T, configure func(cfg *config.Config)) *httptest.Server {
+	t.Helper()
+	cfg, err := config.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.PipelineRegistryUrl = "http://localhost:0"
+	configure(cfg)
+	perm, err := permV2Client.NewTestClient(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := repo.NewMemoryRepo(cfg, perm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := repo.New(cfg, *srv_info_hdl.New("test", "test"), db, operator_api.New("http://localhost:0", cfg.HttpTimeout, 0),
+		*pipelinesClient.NewClient(cfg.PipelineRegistryUrl), nil)
+	handler, err := New(srv, map[string]string{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testToken creates an unsigned token, the mocked permissions do not validate signatures.
+func testToken(userId string) string {
+	encode := func(v any) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return "Bearer " + encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encode(map[string]any{
+		"sub":          userId,
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string][]string{"roles": {"user"}},
+	}) + ".c2lnbmF0dXJl"
+}
+
+func do(t *testing.T, method, url string, body string, header map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), method, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", testToken("owner"))
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestIfMatch(t *testing.T) {
+	server := newTestServer(t)
+	resp := do(t, http.MethodPut, server.URL+"/flow/", `{"name":"a"}`, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	var created lib.FlowCreateResponse
+	err := json.NewDecoder(resp.Body).Decode(&created)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp = do(t, http.MethodGet, server.URL+"/flow/"+created.Id, "", nil)
+	etag := resp.Header.Get(HeaderETag)
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("unexpected status %d with ETag %q", resp.StatusCode, etag)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		ifMatch string
+		status  int
+	}{
+		{"update", http.MethodPost, "/flow/" + created.Id + "/", `{"name":"b"}`, etag, http.StatusOK},
+		{"stale update", http.MethodPost, "/flow/" + created.Id + "/", `{"name":"c"}`, etag, http.StatusPreconditionFailed},
+		{"stale patch", http.MethodPatch, "/flow/" + created.Id, `{"name":"c"}`, etag, http.StatusPreconditionFailed},
+		{"invalid header", http.MethodPost, "/flow/" + created.Id + "/", `{"name":"c"}`, "abc", http.StatusBadRequest},
+		{"wildcard", http.MethodPost, "/flow/" + created.Id + "/", `{"name":"d"}`, "*", http.StatusOK},
+	}
+	for _, test := range tests {
+		header := map[string]string{HeaderIfMatch: test.ifMatch}
+		if test.method == http.MethodPatch {
+			header["Content-Type"] = lib.MergePatchContentType
+		}
+		resp = do(t, test.method, server.URL+test.path, test.body, header)
+		if resp.StatusCode != test.status {
+			t.Fatalf("%s: expected status %d, got %d", test.name, test.status, resp.StatusCode)
+		}
+	}
+
+	resp = do(t, http.MethodGet, server.URL+"/flow/"+created.Id, "", nil)
+	var flow lib.Flow
+	err = json.NewDecoder(resp.Body).Decode(&flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow.Name != "d" || resp.Header.Get(HeaderETag) != formatETag(flow.Version) || resp.Header.Get(HeaderETag) == etag {
+		t.Fatalf("unexpected flow %s with ETag %s", flow.Name, resp.Header.Get(HeaderETag))
+	}
+}
+
+func TestInvalidListArguments(t *testing.T) {
+	server := newTestServer(t)
+	resp := do(t, http.MethodPut, server.URL+"/flow/", `{"name":"a"}`, nil)
+	var created lib.FlowCreateResponse
+	err := json.NewDecoder(resp.Body).Decode(&created)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"/flow?limit=abc",
+		"/flow?offset=abc",
+		"/flow/trash?limit=abc",
+		"/flow/" + created.Id + "/revisions?limit=abc",
+		"/flow/" + created.Id + "/revisions?offset=abc",
+	} {
+		resp = do(t, http.MethodGet, server.URL+path, "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: expected status %d, got %d", path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestInvalidModel(t *testing.T) {
+	server := newTestServer(t)
+	resp := do(t, http.MethodPut, server.URL+"/flow/", `{"name":"a","model":{"cells":[{"id":"l","source":{"id":"x","port":"out"},"target":{"id":"y","port":"in"}}]}}`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	var res lib.ModelErrorResponse
+	err := json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Problems) != 2 || res.Problems[0].CellId != "l" || res.Problems[0].Code != lib.ProblemUnknownNode || res.Error == "" {
+		t.Fatalf("unexpected response %+v", res)
+	}
+}
+
+func TestMetricsRouteLabels(t *testing.T) {
+	server := newTestServer(t)
+	resp := do(t, http.MethodPut, server.URL+"/flow/", `{"name":"a"}`, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+
+	resp = do(t, http.MethodGet, server.URL+MetricsPath, "", nil)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := string(body)
+	// the series of the request and the pre-registered one must be the same
+	if !strings.Contains(metrics, `analytics_flow_repo_http_requests_total{code="201",method="PUT",route="/flow/"}`) {
+		t.Fatal("missing request counter of PUT /flow/")
+	}
+	for _, series := range []string{
+		`analytics_flow_repo_http_request_duration_seconds_count{method="PUT",route="/flow"}`,
+		`analytics_flow_repo_http_request_duration_seconds_count{method="POST",route="/flow/:id"}`,
+		`analytics_flow_repo_http_request_duration_seconds_count{method="DELETE",route="/flow/:id"}`,
+	} {
+		if strings.Contains(metrics, series) {
+			t.Fatalf("unexpected series %s", series)
+		}
+	}
+	for _, series := range []string{
+		`analytics_flow_repo_http_request_duration_seconds_count{method="POST",route="/flow/:id/"}`,
+		`analytics_flow_repo_http_request_duration_seconds_count{method="DELETE",route="/flow/:id/"}`,
+	} {
+		if !strings.Contains(metrics, series) {
+			t.Fatalf("missing series %s", series)
+		}
+	}
+}
+
+func TestClearOperatorCache(t *testing.T) {
+	server := newTestServer(t)
+	resp := do(t, http.MethodDelete, server.URL+"/admin/operator-cache", "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status %d for non admins, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+	resp = do(t, http.MethodDelete, server.URL+"/admin/operator-cache", "", map[string]string{"X-User-Roles": "admin"})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+func TestHealthChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		optional  []string
+		readiness int
+	}{
+		{"required dependencies down", nil, http.StatusServiceUnavailable},
+		{"optional dependencies down", []string{repo.DependencyOperatorRepo, repo.DependencyPipelineRegistry}, http.StatusOK},
+	}
+	for _, test := range tests {
+		server := newTestServerWithConfig(t, func(cfg *config.Config) {
+			cfg.OptionalDependencies = test.optional
+		})
+		// liveness does not depend on the operator repository and pipeline registry, which are not reachable
+		resp := do(t, http.MethodGet, server.URL+HealthCheckPath, "", map[string]string{"Authorization": ""})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: unexpected liveness status %d", test.name, resp.StatusCode)
+		}
+
+		resp = do(t, http.MethodGet, server.URL+ReadinessCheckPath, "", map[string]string{"Authorization": ""})
+		if resp.StatusCode != test.readiness {
+			t.Fatalf("%s: unexpected readiness status %d", test.name, resp.StatusCode)
+		}
+		var report lib.ReadinessReport
+		err := json.NewDecoder(resp.Body).Decode(&report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up := map[string]bool{}
+		for _, status := range report.Dependencies {
+			up[status.Name] = status.Up
+		}
+		if report.Ready != (test.readiness == http.StatusOK) || len(up) != 4 || !up[repo.DependencyMongo] ||
+			!up[repo.DependencyPermissionsV2] || up[repo.DependencyOperatorRepo] || up[repo.DependencyPipelineRegistry] {
+			t.Fatalf("%s: unexpected report %+v", test.name, report)
+		}
+	}
+}
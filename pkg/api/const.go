@@ -21,13 +21,17 @@ const (
 	HeaderApiVer        = "X-Api-Version"
 	HeaderSrvName       = "X-Service"
 	HeaderAuthorization = "Authorization"
+	HeaderETag          = "ETag"
+	HeaderIfMatch       = "If-Match"
 	UserIdKey           = "UserId"
 	AdminKey            = "admin"
 )
 
 const (
-	HealthCheckPath = "/health-check"
-	FlowPath        = "/flow"
+	HealthCheckPath    = "/health-check"
+	ReadinessCheckPath = "/readiness-check"
+	MetricsPath        = "/metrics"
+	FlowPath           = "/flow"
 )
 
 const (
@@ -38,4 +42,6 @@ const (
 	MessageBadInput              = "bad input"
 	MessageStillInUse            = "still in use"
 	MessageExternalResourceError = "external resource error"
+	MessagePreconditionFailed    = "precondition failed"
+	MessageTimeout               = "timeout"
 )
@@ -17,13 +17,33 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/gin-gonic/gin"
 )
 
+// ModelErrorHandler responds with the problems of an invalid flow model as lib.ModelErrorResponse.
+// It has to be used after gin_mw.ErrorHandler, which skips the aborted request.
+func ModelErrorHandler() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		gc.Next()
+		for _, e := range gc.Errors {
+			var me *lib.ModelError
+			if errors.As(e.Err, &me) {
+				gc.AbortWithStatusJSON(http.StatusBadRequest, lib.ModelErrorResponse{Error: me.Error(), Problems: me.Problems})
+				return
+			}
+		}
+	}
+}
+
 func GetStatusCode(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
 	var nfe *lib.NotFoundError
 	if errors.As(err, &nfe) {
 		return http.StatusNotFound
@@ -44,6 +64,10 @@ func GetStatusCode(err error) int {
 	if errors.As(err, &ue) {
 		return http.StatusConflict
 	}
+	var pfe *lib.PreconditionFailedError
+	if errors.As(err, &pfe) {
+		return http.StatusPreconditionFailed
+	}
 	var ee *lib.ExternalResourceError
 	if errors.As(err, &ee) {
 		return http.StatusFailedDependency
@@ -18,10 +18,13 @@ package api
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
 
 	"github.com/gin-gonic/gin"
@@ -49,7 +52,7 @@ func getInfoH(srv Repo) (string, string, gin.HandlerFunc) {
 // @Accept json
 // @Produce json
 // @Success	201 {object} lib.FlowCreateResponse
-// @Failure 400 {string} MessageBadInput
+// @Failure 400 {object} lib.ModelErrorResponse "Invalid flow model, other bad input is returned as MessageBadInput"
 // @Failure 401 {string} MessageUnauthorized
 // @Failure 424 {string} MessageExternalResourceError
 // @Failure 500 {string} MessageSomethingWrong
@@ -62,7 +65,7 @@ func putFlow(srv Repo) (string, string, gin.HandlerFunc) {
 			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
 			return
 		}
-		id, err := srv.CreateFlow(request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		id, err := srv.CreateFlow(gc.Request.Context(), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error creating flow", "error", err)
 			_ = gc.Error(handleError(err))
@@ -74,16 +77,18 @@ func putFlow(srv Repo) (string, string, gin.HandlerFunc) {
 
 // postFlow godoc
 // @Summary Update flow
-// @Description	Validates and updates a flow
+// @Description	Validates and updates a flow. If an If-Match header is given, the update is only applied if it matches the stored flow version.
 // @Tags Flow
 // @Accept json
 // @Param id path string true "Flow ID"
+// @Param If-Match header string false "Expected flow version as returned in the ETag header"
 // @Param flow body lib.Flow	true "Update flow"
 // @Success	200
-// @Failure 400 {string} MessageBadInput
+// @Failure 400 {object} lib.ModelErrorResponse "Invalid flow model, other bad input is returned as MessageBadInput"
 // @Failure 401 {string} MessageUnauthorized
 // @Failure 403 {string} MessageForbidden
 // @Failure 404 {string} MessageNotFound
+// @Failure 412 {string} MessagePreconditionFailed
 // @Failure 424 {string} MessageExternalResourceError
 // @Failure 500 {string} MessageSomethingWrong
 // @Router /flow/{id}/ [post]
@@ -95,7 +100,13 @@ func postFlow(srv Repo) (string, string, gin.HandlerFunc) {
 			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
 			return
 		}
-		err := srv.UpdateFlow(gc.Param("id"), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		expectedVersion, err := parseIfMatch(gc.GetHeader(HeaderIfMatch))
+		if err != nil {
+			util.Logger.Error("error updating flow", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		err = srv.UpdateFlow(gc.Request.Context(), gc.Param("id"), request, expectedVersion, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error updating flow", "error", err)
 			_ = gc.Error(handleError(err))
@@ -105,9 +116,141 @@ func postFlow(srv Repo) (string, string, gin.HandlerFunc) {
 	}
 }
 
+// patchFlow godoc
+// @Summary Patch flow
+// @Description	Partially updates a flow with a JSON merge patch (RFC 7396, also used for application/json) or a JSON patch (RFC 6902). Operators are only validated again if the model changed.
+// @Tags Flow
+// @Accept application/merge-patch+json,application/json-patch+json,json
+// @Param id path string true "Flow ID"
+// @Param If-Match header string false "Expected flow version as returned in the ETag header"
+// @Param patch body object true "Patch document"
+// @Success	200
+// @Failure 400 {object} lib.ModelErrorResponse "Invalid flow model, other bad input is returned as MessageBadInput"
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 412 {string} MessagePreconditionFailed
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id} [patch]
+func patchFlow(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodPatch, FlowPath + "/:id", func(gc *gin.Context) {
+		contentType := gc.ContentType()
+		if contentType == gin.MIMEJSON {
+			contentType = lib.MergePatchContentType
+		}
+		patch, err := io.ReadAll(gc.Request.Body)
+		if err != nil {
+			util.Logger.Error("error patching flow", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		expectedVersion, err := parseIfMatch(gc.GetHeader(HeaderIfMatch))
+		if err != nil {
+			util.Logger.Error("error patching flow", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		err = srv.PatchFlow(gc.Request.Context(), gc.Param("id"), contentType, patch, expectedVersion, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error patching flow", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// postCloneFlow godoc
+// @Summary Clone flow
+// @Description	Copies a flow into a new flow owned by the requesting user. Operators are validated again.
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success	201 {object} lib.FlowCreateResponse
+// @Failure 400 {object} lib.ModelErrorResponse "Invalid flow model, other bad input is returned as MessageBadInput"
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/clone [post]
+func postCloneFlow(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, FlowPath + "/:id/clone", func(gc *gin.Context) {
+		id, err := srv.CloneFlow(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error cloning flow", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusCreated, lib.FlowCreateResponse{Id: id})
+	}
+}
+
+// getExportFlow godoc
+// @Summary Export flow
+// @Description	Exports a flow as portable bundle including the metadata of all referenced operators
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success	200 {object} lib.FlowExport
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/export [get]
+func getExportFlow(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/:id/export", func(gc *gin.Context) {
+		bundle, err := srv.ExportFlow(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error exporting flow", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, bundle)
+	}
+}
+
+// postImportFlow godoc
+// @Summary Import flow
+// @Description	Imports an exported flow. Operators are resolved by name and image. If an operator can not be resolved, no flow is created and the unresolved operators are returned.
+// @Tags Flow
+// @Accept json
+// @Produce json
+// @Param bundle body lib.FlowExport true "Exported flow"
+// @Success	201 {object} lib.FlowImportResponse
+// @Failure 400 {object} lib.ModelErrorResponse "Invalid flow model, other bad input is returned as MessageBadInput"
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 422 {object} lib.FlowImportResponse
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/import [post]
+func postImportFlow(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, FlowPath + "/import", func(gc *gin.Context) {
+		var request lib.FlowExport
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error importing flow", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		response, err := srv.ImportFlow(gc.Request.Context(), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error importing flow", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		if len(response.UnresolvedOperators) > 0 {
+			gc.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+		gc.JSON(http.StatusCreated, response)
+	}
+}
+
 // deleteFlow godoc
 // @Summary Delete flow
-// @Description	Deletes a flow
+// @Description	Moves a flow to the trash
 // @Tags Flow
 // @Param id path string true "Flow ID"
 // @Success	204
@@ -120,7 +263,7 @@ func postFlow(srv Repo) (string, string, gin.HandlerFunc) {
 // @Router /flow/{id}/ [delete]
 func deleteFlow(srv Repo) (string, string, gin.HandlerFunc) {
 	return http.MethodDelete, FlowPath + "/:id/", func(gc *gin.Context) {
-		err := srv.DeleteFlow(gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		err := srv.DeleteFlow(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error deleting flow", "error", err)
 			_ = gc.Error(handleError(err))
@@ -130,11 +273,87 @@ func deleteFlow(srv Repo) (string, string, gin.HandlerFunc) {
 	}
 }
 
+// getDeletedFlows godoc
+// @Summary Get deleted flows
+// @Description	Gets all flows in the trash the user may administrate. Trashed flows are purged after the configured retention.
+// @Tags Flow
+// @Produce json
+// @Success	200 {object} lib.FlowsResponse
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/trash [get]
+func getDeletedFlows(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/trash", func(gc *gin.Context) {
+		args := gc.Request.URL.Query()
+		flows, err := srv.GetDeletedFlows(gc.Request.Context(), gc.GetString(UserIdKey), args, gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting deleted flows", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, flows)
+	}
+}
+
+// postRestoreFlow godoc
+// @Summary Restore flow
+// @Description	Restores a flow from the trash
+// @Tags Flow
+// @Param id path string true "Flow ID"
+// @Success	200
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/restore [post]
+func postRestoreFlow(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, FlowPath + "/:id/restore", func(gc *gin.Context) {
+		err := srv.RestoreFlow(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error restoring flow", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// getTags godoc
+// @Summary Get tags
+// @Description	Gets all distinct tags of the flows visible to the user with the number of flows using them
+// @Tags Flow
+// @Produce json
+// @Success	200 {array} lib.TagCount
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/tags [get]
+func getTags(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/tags", func(gc *gin.Context) {
+		tags, err := srv.GetTags(gc.Request.Context(), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting tags", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, tags)
+	}
+}
+
 // getAll godoc
 // @Summary Get flows
 // @Description	Gets all flows
 // @Tags Flow
 // @Produce json
+// @Param search query string false "full text search on name, description and operator names, results are ranked by relevance"
+// @Param sort query string false "sort field and direction, e.g. name:asc, dateUpdated:desc or score:desc (only with search)"
+// @Param limit query int false "limit"
+// @Param offset query int false "offset, ignored if a cursor is given"
+// @Param cursor query string false "enables cursor pagination, empty for the first page, nextCursor of the previous response for the following ones"
+// @Param filter query string false "filters separated by '|', e.g. operator:id1,id2|tag:tag1,tag2|owner:userId|deploymentType:cloud|dateUpdated:after=2025-01-01,before=2025-02-01T12:00:00Z"
+// @Param total query bool false "set to false to skip counting the total, which is then returned as -1"
+// @Param fields query string false "comma separated list of fields to return, e.g. name,description,dateUpdated"
+// @Param summary query bool false "return the number of nodes and links of each flow instead of the model"
 // @Success	200 {object} lib.FlowsResponse
 // @Failure 401 {string} MessageUnauthorized
 // @Failure 500 {string} MessageSomethingWrong
@@ -142,7 +361,7 @@ func deleteFlow(srv Repo) (string, string, gin.HandlerFunc) {
 func getAll(srv Repo) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, FlowPath, func(gc *gin.Context) {
 		args := gc.Request.URL.Query()
-		flows, err := srv.GetFlows(gc.GetString(UserIdKey), args, gc.GetHeader("Authorization"))
+		flows, err := srv.GetFlows(gc.Request.Context(), gc.GetString(UserIdKey), args, gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error getting flows", "error", err)
 			_ = gc.Error(handleError(err))
@@ -159,6 +378,7 @@ func getAll(srv Repo) (string, string, gin.HandlerFunc) {
 // @Produce json
 // @Param id path string true "Flow ID"
 // @Success	200 {object} lib.Flow
+// @Header 200 {string} ETag "Flow version"
 // @Failure 401 {string} MessageUnauthorized
 // @Failure 403 {string} MessageForbidden
 // @Failure 404 {string} MessageNotFound
@@ -166,19 +386,191 @@ func getAll(srv Repo) (string, string, gin.HandlerFunc) {
 // @Router /flow/{id} [get]
 func getFlow(srv Repo) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, "/flow/:id", func(gc *gin.Context) {
-		flow, err := srv.GetFlow(gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		flow, err := srv.GetFlow(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error getting flow", "error", err)
 			_ = gc.Error(handleError(err))
 			return
 		}
+		gc.Header(HeaderETag, formatETag(flow.Version))
 		gc.JSON(http.StatusOK, flow)
 	}
 }
 
+// getFlowAnalysis godoc
+// @Summary Get flow analysis
+// @Description	Analyzes the topology of a flow and returns its topological order, sources, sinks, cycles, unreachable nodes and unused input ports
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success	200 {object} lib.FlowAnalysis
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/analysis [get]
+func getFlowAnalysis(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/:id/analysis", func(gc *gin.Context) {
+		analysis, err := srv.GetFlowAnalysis(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting flow analysis", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, analysis)
+	}
+}
+
+// getFlowRevisions godoc
+// @Summary Get flow revisions
+// @Description	Lists the stored revisions of a flow, newest first, without their models
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Param limit query int false "limit"
+// @Param offset query int false "offset"
+// @Success	200 {object} lib.FlowRevisionsResponse
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/revisions [get]
+func getFlowRevisions(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/:id/revisions", func(gc *gin.Context) {
+		args := gc.Request.URL.Query()
+		revisions, err := srv.GetFlowRevisions(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), args, gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting flow revisions", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, revisions)
+	}
+}
+
+// getFlowRevision godoc
+// @Summary Get flow revision
+// @Description	Gets a single revision of a flow including its model
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Param rev path int true "Revision"
+// @Success	200 {object} lib.FlowRevision
+// @Failure 400 {string} MessageBadInput
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/revisions/{rev} [get]
+func getFlowRevision(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/:id/revisions/:rev", func(gc *gin.Context) {
+		revision, err := strconv.ParseInt(gc.Param("rev"), 10, 64)
+		if err != nil {
+			util.Logger.Error("error getting flow revision", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		flowRevision, err := srv.GetFlowRevision(gc.Request.Context(), gc.Param("id"), revision, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting flow revision", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, flowRevision)
+	}
+}
+
+// postRestoreFlowRevision godoc
+// @Summary Restore flow revision
+// @Description	Restores name, description and model of a flow from a revision. The restored state is validated and stored as a new revision.
+// @Tags Flow
+// @Param id path string true "Flow ID"
+// @Param rev path int true "Revision"
+// @Success	200
+// @Failure 400 {object} lib.ModelErrorResponse "Invalid flow model, other bad input is returned as MessageBadInput"
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 404 {string} MessageNotFound
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/revisions/{rev}/restore [post]
+func postRestoreFlowRevision(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, FlowPath + "/:id/revisions/:rev/restore", func(gc *gin.Context) {
+		revision, err := strconv.ParseInt(gc.Param("rev"), 10, 64)
+		if err != nil {
+			util.Logger.Error("error restoring flow revision", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		err = srv.RestoreFlowRevision(gc.Request.Context(), gc.Param("id"), revision, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error restoring flow revision", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// getFlowPermissions godoc
+// @Summary Get flow permissions
+// @Description	Gets the user, group and role permissions of a flow. Requires the administrate permission.
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success	200 {object} lib.FlowPermissions
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/permissions [get]
+func getFlowPermissions(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, FlowPath + "/:id/permissions", func(gc *gin.Context) {
+		permissions, err := srv.GetFlowPermissions(gc.Request.Context(), gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting flow permissions", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, permissions)
+	}
+}
+
+// putFlowPermissions godoc
+// @Summary Set flow permissions
+// @Description	Replaces the user, group and role permissions of a flow. Requires the administrate permission, at least one user has to keep it.
+// @Tags Flow
+// @Accept json
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Param permissions body lib.FlowPermissions true "Flow permissions"
+// @Success	200 {object} lib.FlowPermissions
+// @Failure 400 {string} MessageBadInput
+// @Failure 401 {string} MessageUnauthorized
+// @Failure 403 {string} MessageForbidden
+// @Failure 424 {string} MessageExternalResourceError
+// @Failure 500 {string} MessageSomethingWrong
+// @Router /flow/{id}/permissions [put]
+func putFlowPermissions(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, FlowPath + "/:id/permissions", func(gc *gin.Context) {
+		var request lib.FlowPermissions
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error setting flow permissions", "error", err)
+			_ = gc.Error(lib.NewInputError(errors.New(MessageBadInput)))
+			return
+		}
+		permissions, err := srv.SetFlowPermissions(gc.Request.Context(), gc.Param("id"), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error setting flow permissions", "error", err)
+			_ = gc.Error(handleError(err))
+			return
+		}
+		gc.JSON(http.StatusOK, permissions)
+	}
+}
+
 func getOperatorUsageAdmin(srv Repo) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, "/admin/statistics/operator-usage", func(gc *gin.Context) {
-		data, err := srv.GetOperatorUsage()
+		data, err := srv.GetOperatorUsage(gc.Request.Context())
 		if err != nil {
 			util.Logger.Error("error getting operator usage", "error", err)
 			_ = gc.Error(handleError(err))
@@ -188,6 +580,21 @@ func getOperatorUsageAdmin(srv Repo) (string, string, gin.HandlerFunc) {
 	}
 }
 
+func getOperatorCacheStatsAdmin(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/admin/statistics/operator-cache", func(gc *gin.Context) {
+		gc.JSON(http.StatusOK, srv.GetOperatorCacheStats(gc.Request.Context()))
+	}
+}
+
+func deleteOperatorCacheAdmin(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, "/admin/operator-cache", func(gc *gin.Context) {
+		srv.ClearOperatorCache(gc.Request.Context())
+		gc.Status(http.StatusNoContent)
+	}
+}
+
+// getHealthCheckH is the liveness check. Repo.HealthCheck returns nil on purpose, dependencies are only checked by the readiness check,
+// so an outage of a dependency does not restart the service.
 func getHealthCheckH(srv Repo) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, HealthCheckPath, func(gc *gin.Context) {
 		err := srv.HealthCheck(gc.Request.Context())
@@ -199,6 +606,22 @@ func getHealthCheckH(srv Repo) (string, string, gin.HandlerFunc) {
 	}
 }
 
+// getReadinessCheckH responds with 503 if a required dependency is down, the body always lists the state of all dependencies.
+func getReadinessCheckH(srv Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, ReadinessCheckPath, func(gc *gin.Context) {
+		report := srv.Readiness(gc.Request.Context())
+		if !report.Ready {
+			gc.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		gc.JSON(http.StatusOK, report)
+	}
+}
+
+func getMetricsH(_ Repo) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, MetricsPath, gin.WrapH(metrics.Handler())
+}
+
 func getSwaggerDocH(_ Repo) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, "/doc", func(gc *gin.Context) {
 		if _, err := os.Stat("docs/swagger.json"); err != nil {
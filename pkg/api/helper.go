@@ -17,7 +17,10 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -29,9 +32,15 @@ func handleError(err error) error {
 	}
 
 	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return timeoutError{}
+
 	case errors.Is(err, mongo.ErrNoDocuments):
 		return lib.NewNotFoundError(errors.New(MessageNotFound))
 
+	case errors.As(err, new(*lib.InputError)):
+		return err
+
 	case errors.As(err, new(*lib.NotFoundError)):
 		return lib.NewNotFoundError(errors.New(MessageNotFound))
 
@@ -41,6 +50,9 @@ func handleError(err error) error {
 	case errors.As(err, new(*lib.StillInUseError)):
 		return lib.NewStillInUseError(nil, errors.New(MessageStillInUse))
 
+	case errors.As(err, new(*lib.PreconditionFailedError)):
+		return lib.NewPreconditionFailedError(errors.New(MessagePreconditionFailed))
+
 	case errors.As(err, new(*lib.ExternalResourceError)):
 		return lib.NewExternalResourceError(errors.New(MessageExternalResourceError))
 
@@ -48,3 +60,32 @@ func handleError(err error) error {
 		return lib.NewInternalError(errors.New(MessageSomethingWrong))
 	}
 }
+
+// timeoutError hides the cause of an exceeded deadline, while GetStatusCode can still match it.
+type timeoutError struct{}
+
+func (timeoutError) Error() string {
+	return MessageTimeout
+}
+
+func (timeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch returns the flow version of an If-Match header, nil if the header is empty or a wildcard.
+func parseIfMatch(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	header = strings.Trim(strings.TrimPrefix(header, "W/"), "\"")
+	version, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
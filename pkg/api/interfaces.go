@@ -26,10 +26,26 @@ import (
 type Repo interface {
 	SrvInfo(ctx context.Context) srv_info_hdl.ServiceInfo
 	HealthCheck(ctx context.Context) error
-	CreateFlow(flow lib.Flow, userId string, authString string) (id string, err error)
-	UpdateFlow(id string, flow lib.Flow, userId string, authString string) (err error)
-	DeleteFlow(id, userId, auth string) (err error)
-	GetFlows(userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error)
-	GetFlow(flowId, userId, auth string) (response lib.Flow, err error)
-	GetOperatorUsage() ([]lib.OperatorFlowCount, error)
+	Readiness(ctx context.Context) lib.ReadinessReport
+	CreateFlow(ctx context.Context, flow lib.Flow, userId string, authString string) (id string, err error)
+	UpdateFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64, userId string, authString string) (err error)
+	PatchFlow(ctx context.Context, id string, contentType string, patch []byte, expectedVersion *int64, userId string, authString string) (err error)
+	CloneFlow(ctx context.Context, id, userId, auth string) (cloneId string, err error)
+	ExportFlow(ctx context.Context, id, userId, auth string) (bundle lib.FlowExport, err error)
+	ImportFlow(ctx context.Context, bundle lib.FlowExport, userId, auth string) (response lib.FlowImportResponse, err error)
+	DeleteFlow(ctx context.Context, id, userId, auth string) (err error)
+	GetFlows(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error)
+	GetFlow(ctx context.Context, flowId, userId, auth string) (response lib.Flow, err error)
+	GetTags(ctx context.Context, userId, auth string) (tags []lib.TagCount, err error)
+	GetDeletedFlows(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error)
+	RestoreFlow(ctx context.Context, id, userId, auth string) (err error)
+	GetFlowAnalysis(ctx context.Context, flowId, userId, auth string) (analysis lib.FlowAnalysis, err error)
+	GetFlowRevisions(ctx context.Context, flowId, userId string, args map[string][]string, auth string) (response lib.FlowRevisionsResponse, err error)
+	GetFlowRevision(ctx context.Context, flowId string, revision int64, userId, auth string) (response lib.FlowRevision, err error)
+	RestoreFlowRevision(ctx context.Context, flowId string, revision int64, userId, auth string) (err error)
+	GetFlowPermissions(ctx context.Context, flowId, userId, auth string) (permissions lib.FlowPermissions, err error)
+	SetFlowPermissions(ctx context.Context, flowId string, permissions lib.FlowPermissions, userId, auth string) (result lib.FlowPermissions, err error)
+	GetOperatorUsage(ctx context.Context) ([]lib.OperatorFlowCount, error)
+	GetOperatorCacheStats(ctx context.Context) lib.OperatorCacheStats
+	ClearOperatorCache(ctx context.Context)
 }
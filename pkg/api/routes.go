@@ -21,17 +21,34 @@ import gin_mw "github.com/SENERGY-Platform/gin-middleware"
 var routes = gin_mw.Routes[Repo]{
 	getInfoH,
 	getHealthCheckH,
+	getReadinessCheckH,
+	getMetricsH,
 	getSwaggerDocH,
 }
 
 var routesAuth = gin_mw.Routes[Repo]{
 	getAll,
 	getFlow,
+	getFlowAnalysis,
+	getFlowRevisions,
+	getFlowRevision,
+	postRestoreFlowRevision,
 	putFlow,
 	postFlow,
+	patchFlow,
+	postCloneFlow,
+	getExportFlow,
+	postImportFlow,
+	getFlowPermissions,
+	putFlowPermissions,
 	deleteFlow,
+	getDeletedFlows,
+	getTags,
+	postRestoreFlow,
 }
 
 var routesAdmin = gin_mw.Routes[Repo]{
 	getOperatorUsageAdmin,
+	getOperatorCacheStatsAdmin,
+	deleteOperatorCacheAdmin,
 }
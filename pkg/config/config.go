@@ -17,20 +17,48 @@
 package config
 
 import (
+	"net/url"
 	"time"
 
 	"github.com/SENERGY-Platform/go-service-base/config-hdl"
 )
 
 type Config struct {
-	ServerPort          int           `json:"server_port" env_var:"SERVER_PORT"`
-	Logger              LoggerConfig  `json:"logger" env_var:"LOGGER_CONFIG"`
-	MongoUrl            string        `json:"mongo_url" env_var:"MONGO_URL"`
-	HttpTimeout         time.Duration `json:"http_timeout" env_var:"HTTP_TIMEOUT"`
-	PermissionsV2Url    string        `json:"permissions_v2_url" env_var:"PERMISSIONS_V2_URL"`
-	OperatorRepoUrl     string        `json:"operator_repo_url" env_var:"OPERATOR_REPO_URL"`
-	PipelineRegistryUrl string        `json:"pipeline_registry_url" env_var:"PIPELINE_REGISTRY_URL"`
-	URLPrefix           string        `json:"url_prefix" env_var:"URL_PREFIX"`
+	ServerPort                int           `json:"server_port" env_var:"SERVER_PORT"`
+	Logger                    LoggerConfig  `json:"logger" env_var:"LOGGER_CONFIG"`
+	MongoUrl                  string        `json:"mongo_url" env_var:"MONGO_URL"`
+	MongoDatabase             string        `json:"mongo_database" env_var:"MONGO_DATABASE"`
+	MongoFlowsCollection      string        `json:"mongo_flows_collection" env_var:"MONGO_FLOWS_COLLECTION"`
+	MongoRevisionsCollection  string        `json:"mongo_revisions_collection" env_var:"MONGO_REVISIONS_COLLECTION"`
+	MongoMigrationsCollection string        `json:"mongo_migrations_collection" env_var:"MONGO_MIGRATIONS_COLLECTION"`
+	MongoOutboxCollection     string        `json:"mongo_outbox_collection" env_var:"MONGO_OUTBOX_COLLECTION"`
+	HttpTimeout               time.Duration `json:"http_timeout" env_var:"HTTP_TIMEOUT"`
+	PermissionsV2Url          string        `json:"permissions_v2_url" env_var:"PERMISSIONS_V2_URL"`
+	OperatorRepoUrl           string        `json:"operator_repo_url" env_var:"OPERATOR_REPO_URL"`
+	OperatorCacheTTL          time.Duration `json:"operator_cache_ttl" env_var:"OPERATOR_CACHE_TTL"`
+	OperatorFetchConcurrency  int           `json:"operator_fetch_concurrency" env_var:"OPERATOR_FETCH_CONCURRENCY"`
+	PipelineRegistryUrl       string        `json:"pipeline_registry_url" env_var:"PIPELINE_REGISTRY_URL"`
+	URLPrefix                 string        `json:"url_prefix" env_var:"URL_PREFIX"`
+	StrictFlowAnalysis        bool          `json:"strict_flow_analysis" env_var:"STRICT_FLOW_ANALYSIS"`
+	RevisionLimit             int           `json:"revision_limit" env_var:"REVISION_LIMIT"`
+	RevisionMaxAge            time.Duration `json:"revision_max_age" env_var:"REVISION_MAX_AGE"`
+	CloneNameSuffix           string        `json:"clone_name_suffix" env_var:"CLONE_NAME_SUFFIX"`
+	TrashRetention            time.Duration `json:"trash_retention" env_var:"TRASH_RETENTION"`
+	TrashPurgeInterval        time.Duration `json:"trash_purge_interval" env_var:"TRASH_PURGE_INTERVAL"`
+	OptionalDependencies      []string      `json:"optional_dependencies" env_var:"OPTIONAL_DEPENDENCIES"`
+	FlowMetricsInterval       time.Duration `json:"flow_metrics_interval" env_var:"FLOW_METRICS_INTERVAL"`
+	KafkaUrl                  string        `json:"kafka_url" env_var:"KAFKA_URL"`
+	FlowEventsTopic           string        `json:"flow_events_topic" env_var:"FLOW_EVENTS_TOPIC"`
+	OutboxInterval            time.Duration `json:"outbox_interval" env_var:"OUTBOX_INTERVAL"`
+	OutboxBatchSize           int64         `json:"outbox_batch_size" env_var:"OUTBOX_BATCH_SIZE"`
+}
+
+// Redacted returns a copy of the config without credentials in connection strings, suitable for logging.
+func (c Config) Redacted() Config {
+	if u, err := url.Parse(c.MongoUrl); err == nil {
+		c.MongoUrl = u.Redacted()
+	}
+	return c
 }
 
 type LoggerConfig struct {
@@ -43,11 +71,26 @@ func New(path string) (*Config, error) {
 		Logger: LoggerConfig{
 			Level: "info",
 		},
-		MongoUrl:            "localhost:27017",
-		HttpTimeout:         time.Second * 30,
-		PermissionsV2Url:    "http://permv2.permissions:8080",
-		OperatorRepoUrl:     "http://operator-repo:8080",
-		PipelineRegistryUrl: "http://api.analytics-pipeline-service:8000",
+		MongoUrl:                  "localhost:27017",
+		MongoDatabase:             "flow_database",
+		MongoFlowsCollection:      "flows",
+		MongoRevisionsCollection:  "revisions",
+		MongoMigrationsCollection: "migrations",
+		MongoOutboxCollection:     "outbox",
+		HttpTimeout:               time.Second * 30,
+		PermissionsV2Url:          "http://permv2.permissions:8080",
+		OperatorRepoUrl:           "http://operator-repo:8080",
+		OperatorCacheTTL:          time.Minute,
+		OperatorFetchConcurrency:  8,
+		PipelineRegistryUrl:       "http://api.analytics-pipeline-service:8000",
+		RevisionLimit:             50,
+		CloneNameSuffix:           " (copy)",
+		TrashRetention:            time.Hour * 24 * 30,
+		TrashPurgeInterval:        time.Hour,
+		FlowMetricsInterval:       time.Minute,
+		FlowEventsTopic:           "analytics-flow-events",
+		OutboxInterval:            time.Second * 10,
+		OutboxBatchSize:           100,
 	}
 	err := config_hdl.Load(&cfg, nil, envTypeParser, nil, path)
 	return &cfg, err
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+// MemoryBroker is an in-process stand-in for kafka. It keeps all published events and can simulate an unavailable broker.
+type MemoryBroker struct {
+	mux    sync.Mutex
+	events []lib.FlowEvent
+	err    error
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, events []lib.FlowEvent) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	b.events = append(b.events, events...)
+	return nil
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
+
+// Events returns all events published so far in order of publication.
+func (b *MemoryBroker) Events() []lib.FlowEvent {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return slices.Clone(b.events)
+}
+
+// SetError makes all following publications fail with err until it is reset with nil.
+func (b *MemoryBroker) SetError(err error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.err = err
+}
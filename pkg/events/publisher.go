@@ -0,0 +1,29 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package events
+
+import (
+	"context"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+// Publisher delivers flow events to other services. Publish either delivers all events or returns an error,
+// in which case the caller retries them later.
+type Publisher interface {
+	Publish(ctx context.Context, events []lib.FlowEvent) error
+	Close() error
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "analytics_flow_repo"
+
+// targets of outbound calls
+const (
+	TargetPermissionsV2    = "permissions_v2"
+	TargetOperatorRepo     = "operator_repo"
+	TargetPipelineRegistry = "pipeline_registry"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Handled http requests by route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of http requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	mongoOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "Duration of flow repository operations on mongodb.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	outboundCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbound_calls_total",
+		Help:      "Calls to other services by target and result.",
+	}, []string{"target", "result"})
+
+	operatorCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operator_cache_lookups_total",
+		Help:      "Lookups in the operator cache by result.",
+	}, []string{"result"})
+
+	flowsTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "flows",
+		Help:      "Number of flows which are not deleted.",
+	})
+
+	operatorFlows = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "operator_flows",
+		Help:      "Number of flows using an operator.",
+	}, []string{"operator_id"})
+)
+
+// Handler serves all registered metrics in the prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InitHttpRoutes creates the duration histograms of all routes, so routes without requests are exported too.
+func InitHttpRoutes(routes [][2]string) {
+	for _, route := range routes {
+		httpRequestDuration.WithLabelValues(route[0], route[1])
+	}
+}
+
+func ObserveHttpRequest(method, route string, code int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveMongoOperation records the duration since start. It is meant to be deferred right before the database calls of an operation,
+// calls to other services like permissions-v2 must not be measured.
+func ObserveMongoOperation(operation string, start time.Time) {
+	mongoOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+func CountOutboundCall(target string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	outboundCalls.WithLabelValues(target, result).Inc()
+}
+
+func CountOperatorCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	operatorCacheLookups.WithLabelValues(result).Inc()
+}
+
+func SetFlows(count int64) {
+	flowsTotal.Set(float64(count))
+}
+
+// SetOperatorFlows replaces the flow counts of all operators, so operators which are no longer used are removed.
+func SetOperatorFlows(counts map[string]int) {
+	operatorFlows.Reset()
+	for operatorId, count := range counts {
+		operatorFlows.WithLabelValues(operatorId).Set(float64(count))
+	}
+}
@@ -17,35 +17,155 @@
 package operator_api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"strconv"
+	"net/url"
+	"time"
 
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
 	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
-	"github.com/parnurzeal/gorequest"
+)
+
+const (
+	maxAttempts    = 3
+	initialBackoff = 100 * time.Millisecond
 )
 
 type Repo struct {
-	url string
+	url     string
+	timeout time.Duration
+	client  *http.Client
+	cache   *cache
 }
 
-func New(url string) *Repo {
-	return &Repo{url}
+// New creates an operator repo client. Each call including retries is bounded by timeout,
+// operators are cached for cacheTtl. A cacheTtl of 0 disables the cache.
+func New(url string, timeout time.Duration, cacheTtl time.Duration) *Repo {
+	return &Repo{
+		url:     url,
+		timeout: timeout,
+		client:  &http.Client{},
+		cache:   newCache(cacheTtl),
+	}
 }
 
-func (a Repo) GetOperator(id, userId, authorization string) (o operator_repo.Operator, err error) {
-	request := gorequest.New()
-	request.Get(a.url+"/operator/"+id).Set("X-UserId", userId).Set("Authorization", authorization)
-	resp, body, e := request.End()
-	if resp.StatusCode != http.StatusOK {
-		err = errors.New("operator API - could not get operator from operator service: " + strconv.Itoa(resp.StatusCode) + " " + body)
+// GetOperator returns the operator with the given id. An operator which does not exist or is not visible to the user results in a lib.InputError.
+func (a *Repo) GetOperator(ctx context.Context, id, userId, authorization string) (o operator_repo.Operator, err error) {
+	o, ok := a.cache.get(userId, id)
+	if ok {
 		return
 	}
-	if len(e) > 0 {
-		err = errors.New("operator API - could not get operator from operator service: an error occurred")
-		return
+	err = a.get(ctx, "/operator/"+url.PathEscape(id), userId, authorization, &o)
+	var se *statusError
+	if errors.As(err, &se) && (se.code == http.StatusNotFound || se.code == http.StatusForbidden) {
+		return o, lib.NewInputError(errors.New("operator " + id + " does not exist"))
+	}
+	if err != nil {
+		return o, lib.NewExternalResourceError(fmt.Errorf("could not get operator %s from operator repo: %w", id, err))
+	}
+	a.cache.set(userId, id, o)
+	return
+}
+
+func (a *Repo) GetOperators(ctx context.Context, userId, authorization string, args url.Values) (o operator_repo.OperatorResponse, err error) {
+	err = a.get(ctx, "/operator?"+args.Encode(), userId, authorization, &o)
+	if err != nil {
+		return o, lib.NewExternalResourceError(fmt.Errorf("could not get operators from operator repo: %w", err))
 	}
-	err = json.Unmarshal([]byte(body), &o)
 	return
 }
+
+// CacheStats returns the hit and miss counts of the operator cache.
+func (a *Repo) CacheStats() lib.OperatorCacheStats {
+	return a.cache.stats()
+}
+
+// ClearCache removes all cached operators, e.g. after an operator was changed.
+func (a *Repo) ClearCache() {
+	a.cache.clear()
+}
+
+// HealthCheck checks if the operator repo is reachable. Unlike other calls it is not retried.
+func (a *Repo) HealthCheck(ctx context.Context) (err error) {
+	defer func() {
+		metrics.CountOutboundCall(metrics.TargetOperatorRepo, err)
+	}()
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url+"/health-check", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &statusError{code: resp.StatusCode, body: string(body)}
+	}
+	return nil
+}
+
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.code, e.body)
+}
+
+// get decodes the response of a GET request into result. Connection errors and server errors are retried with exponential backoff.
+func (a *Repo) get(ctx context.Context, path, userId, authorization string, result interface{}) (err error) {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = a.do(ctx, path, userId, authorization, result)
+		if !retry || attempt == maxAttempts {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (a *Repo) do(ctx context.Context, path, userId, authorization string, result interface{}) (retry bool, err error) {
+	defer func() {
+		metrics.CountOutboundCall(metrics.TargetOperatorRepo, err)
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-UserId", userId)
+	req.Header.Set("Authorization", authorization)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode >= http.StatusInternalServerError, &statusError{code: resp.StatusCode, body: string(body)}
+	}
+	return false, json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator_api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// testServer responds to each request with the next status code and serves op once the codes are used up.
+func testServer(t *testing.T, op operator_repo.Operator, codes ...int) (*httptest.Server, *atomic.Int64) {
+	requests := new(atomic.Int64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if r.Header.Get("X-UserId") != "user" || r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if int(n) <= len(codes) {
+			w.WriteHeader(codes[n-1])
+			return
+		}
+		_ = json.NewEncoder(w).Encode(op)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestGetOperatorRetry(t *testing.T) {
+	id := bson.NewObjectID()
+	server, requests := testServer(t, operator_repo.Operator{Id: &id, Name: "op"}, http.StatusBadGateway, http.StatusServiceUnavailable)
+	op, err := New(server.URL, time.Second, 0).GetOperator(t.Context(), id.Hex(), "user", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Name != "op" || requests.Load() != 3 {
+		t.Fatalf("unexpected operator %+v after %d requests", op, requests.Load())
+	}
+}
+
+func TestGetOperatorRetriesExhausted(t *testing.T) {
+	id := bson.NewObjectID()
+	server, requests := testServer(t, operator_repo.Operator{Id: &id}, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError)
+	_, err := New(server.URL, time.Second, 0).GetOperator(t.Context(), id.Hex(), "user", "token")
+	if !errors.As(err, new(*lib.ExternalResourceError)) {
+		t.Fatalf("expected external resource error, got %v", err)
+	}
+	if requests.Load() != maxAttempts {
+		t.Fatalf("expected %d requests, got %d", maxAttempts, requests.Load())
+	}
+}
+
+func TestGetOperatorNotFound(t *testing.T) {
+	id := bson.NewObjectID()
+	server, requests := testServer(t, operator_repo.Operator{Id: &id}, http.StatusNotFound)
+	_, err := New(server.URL, time.Second, 0).GetOperator(t.Context(), id.Hex(), "user", "token")
+	if !errors.As(err, new(*lib.InputError)) || !strings.Contains(err.Error(), id.Hex()) {
+		t.Fatalf("expected input error naming %s, got %v", id.Hex(), err)
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("client errors must not be retried, got %d requests", requests.Load())
+	}
+}
+
+func TestGetOperatorTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	_, err := New(server.URL, 50*time.Millisecond, 0).GetOperator(t.Context(), bson.NewObjectID().Hex(), "user", "token")
+	if !errors.As(err, new(*lib.ExternalResourceError)) {
+		t.Fatalf("expected external resource error, got %v", err)
+	}
+}
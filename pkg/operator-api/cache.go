@@ -0,0 +1,109 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator_api
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+)
+
+// cache keeps operators for a limited time. Entries are kept per user, because operators are not visible to everyone.
+type cache struct {
+	ttl       time.Duration
+	mux       sync.Mutex
+	entries   map[cacheKey]cacheEntry
+	lastPrune time.Time
+	hits      atomic.Int64
+	misses    atomic.Int64
+}
+
+type cacheKey struct {
+	userId string
+	id     string
+}
+
+type cacheEntry struct {
+	operator operator_repo.Operator
+	expires  time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:       ttl,
+		entries:   map[cacheKey]cacheEntry{},
+		lastPrune: time.Now(),
+	}
+}
+
+func (c *cache) get(userId, id string) (operator_repo.Operator, bool) {
+	if c.ttl <= 0 {
+		return operator_repo.Operator{}, false
+	}
+	c.mux.Lock()
+	entry, ok := c.entries[cacheKey{userId, id}]
+	c.mux.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		c.misses.Add(1)
+		metrics.CountOperatorCacheLookup(false)
+		return operator_repo.Operator{}, false
+	}
+	c.hits.Add(1)
+	metrics.CountOperatorCacheLookup(true)
+	return entry.operator, true
+}
+
+func (c *cache) set(userId, id string, operator operator_repo.Operator) {
+	if c.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	// expired entries are removed at most once per ttl, so the cache does not grow with every user ever seen
+	if now.Sub(c.lastPrune) > c.ttl {
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastPrune = now
+	}
+	c.entries[cacheKey{userId, id}] = cacheEntry{operator: operator, expires: now.Add(c.ttl)}
+}
+
+// clear removes all entries, the hit and miss counts are kept.
+func (c *cache) clear() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.entries = map[cacheKey]cacheEntry{}
+}
+
+func (c *cache) stats() lib.OperatorCacheStats {
+	c.mux.Lock()
+	entries := len(c.entries)
+	c.mux.Unlock()
+	return lib.OperatorCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator_api
+
+import (
+	"testing"
+	"time"
+
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheLookups returns the exported lookup counter of the given result.
+func cacheLookups(t *testing.T, result string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "analytics_flow_repo_operator_cache_lookups_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == result {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestCache(t *testing.T) {
+	hits, misses := cacheLookups(t, "hit"), cacheLookups(t, "miss")
+	c := newCache(time.Minute)
+	c.set("a", "op", operator_repo.Operator{Name: "op"})
+
+	op, ok := c.get("a", "op")
+	if !ok || op.Name != "op" {
+		t.Fatalf("expected hit, got %v %+v", ok, op)
+	}
+	_, ok = c.get("b", "op")
+	if ok {
+		t.Fatal("operator of user a visible to user b")
+	}
+	_, ok = c.get("a", "other")
+	if ok {
+		t.Fatal("unexpected hit of unknown operator")
+	}
+	stats := c.stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if cacheLookups(t, "hit")-hits != 1 || cacheLookups(t, "miss")-misses != 2 {
+		t.Fatal("lookups not exported")
+	}
+
+	c.clear()
+	_, ok = c.get("a", "op")
+	if ok {
+		t.Fatal("unexpected hit after clear")
+	}
+	stats = c.stats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Entries != 0 {
+		t.Fatalf("unexpected stats after clear %+v", stats)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := newCache(20 * time.Millisecond)
+	c.set("a", "op", operator_repo.Operator{})
+	_, ok := c.get("a", "op")
+	if !ok {
+		t.Fatal("expected hit")
+	}
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.get("a", "op")
+	if ok {
+		t.Fatal("unexpected hit of expired entry")
+	}
+	// the next set prunes expired entries
+	c.set("a", "other", operator_repo.Operator{})
+	if entries := c.stats().Entries; entries != 1 {
+		t.Fatalf("expected 1 entry after pruning, got %d", entries)
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := newCache(0)
+	c.set("a", "op", operator_repo.Operator{})
+	_, ok := c.get("a", "op")
+	if ok || c.stats().Entries != 0 {
+		t.Fatal("disabled cache stored an operator")
+	}
+}
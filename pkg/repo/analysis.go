@@ -0,0 +1,247 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+type flowGraph struct {
+	nodes    []string
+	inPorts  map[string][]string
+	out      map[string][]string
+	in       map[string][]string
+	usedPort map[lib.NodePort]bool
+}
+
+// newFlowGraph builds a directed graph from the node and link cells of a model.
+// Links with an endpoint that does not resolve to a node are ignored, see validateModel.
+func newFlowGraph(model lib.Model) *flowGraph {
+	g := &flowGraph{
+		inPorts:  map[string][]string{},
+		out:      map[string][]string{},
+		in:       map[string][]string{},
+		usedPort: map[lib.NodePort]bool{},
+	}
+	for _, cell := range model.Cells {
+		if isLink(cell) || cell.Id == "" {
+			continue
+		}
+		if _, ok := g.inPorts[cell.Id]; ok {
+			continue
+		}
+		g.nodes = append(g.nodes, cell.Id)
+		g.inPorts[cell.Id] = cell.InPorts
+	}
+	for _, cell := range model.Cells {
+		if cell.Source == nil || cell.Target == nil {
+			continue
+		}
+		src, dst := cell.Source.Id, cell.Target.Id
+		if _, ok := g.inPorts[src]; !ok {
+			continue
+		}
+		if _, ok := g.inPorts[dst]; !ok {
+			continue
+		}
+		g.out[src] = append(g.out[src], dst)
+		g.in[dst] = append(g.in[dst], src)
+		g.usedPort[lib.NodePort{NodeId: dst, Port: cell.Target.Port}] = true
+	}
+	return g
+}
+
+// analyzeModel returns the topology of a flow model. The topological order is only set if the model is acyclic.
+func analyzeModel(model lib.Model) lib.FlowAnalysis {
+	g := newFlowGraph(model)
+	analysis := lib.FlowAnalysis{
+		TopologicalOrder: []string{},
+		Sources:          []string{},
+		Sinks:            []string{},
+		Cycles:           g.cycles(),
+		UnreachableNodes: g.unreachable(),
+		UnusedInputPorts: []lib.NodePort{},
+		Problems:         []lib.ModelProblem{},
+	}
+	for _, cycle := range analysis.Cycles {
+		for _, node := range cycle {
+			analysis.Problems = append(analysis.Problems, lib.ModelProblem{CellId: node, Code: lib.ProblemCycle,
+				Message: fmt.Sprintf("part of cycle %s", strings.Join(cycle, " -> "))})
+		}
+	}
+	for _, node := range analysis.UnreachableNodes {
+		analysis.Problems = append(analysis.Problems, lib.ModelProblem{CellId: node, Code: lib.ProblemUnreachableNode, Message: "not connected to the rest of the flow"})
+	}
+	for _, node := range g.nodes {
+		if len(g.in[node]) == 0 {
+			analysis.Sources = append(analysis.Sources, node)
+		}
+		if len(g.out[node]) == 0 {
+			analysis.Sinks = append(analysis.Sinks, node)
+		}
+		for _, port := range g.inPorts[node] {
+			p := lib.NodePort{NodeId: node, Port: port}
+			if !g.usedPort[p] {
+				analysis.UnusedInputPorts = append(analysis.UnusedInputPorts, p)
+				analysis.Problems = append(analysis.Problems, lib.ModelProblem{CellId: node, Port: port, Code: lib.ProblemUnusedInputPort, Message: fmt.Sprintf("input port '%s' is not connected", port)})
+			}
+		}
+	}
+	if len(analysis.Cycles) == 0 {
+		analysis.TopologicalOrder = g.topologicalOrder()
+	}
+	return analysis
+}
+
+// topologicalOrder uses Kahn's algorithm and keeps the cell order of the model for independent nodes.
+func (g *flowGraph) topologicalOrder() []string {
+	inDegree := map[string]int{}
+	var queue []string
+	for _, node := range g.nodes {
+		inDegree[node] = len(g.in[node])
+		if inDegree[node] == 0 {
+			queue = append(queue, node)
+		}
+	}
+	order := make([]string, 0, len(g.nodes))
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		order = append(order, node)
+		for _, next := range g.out[node] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+	return order
+}
+
+// cycles returns the strongly connected components with more than one node or a self loop (Tarjan).
+func (g *flowGraph) cycles() [][]string {
+	index := 0
+	indices := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	result := [][]string{}
+
+	var connect func(node string)
+	connect = func(node string) {
+		indices[node] = index
+		lowLink[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, next := range g.out[node] {
+			if _, visited := indices[next]; !visited {
+				connect(next)
+				lowLink[node] = min(lowLink[node], lowLink[next])
+			} else if onStack[next] {
+				lowLink[node] = min(lowLink[node], indices[next])
+			}
+		}
+		if lowLink[node] != indices[node] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == node {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(g.out[node], node) {
+			slices.Reverse(component)
+			result = append(result, component)
+		}
+	}
+
+	for _, node := range g.nodes {
+		if _, visited := indices[node]; !visited {
+			connect(node)
+		}
+	}
+	return result
+}
+
+// unreachable returns all nodes that are not connected to the largest island of the flow, ignoring link direction.
+func (g *flowGraph) unreachable() []string {
+	component := map[string]int{}
+	var sizes []int
+	for _, node := range g.nodes {
+		if _, ok := component[node]; ok {
+			continue
+		}
+		id := len(sizes)
+		sizes = append(sizes, 0)
+		queue := []string{node}
+		component[node] = id
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			sizes[id]++
+			for _, next := range slices.Concat(g.out[current], g.in[current]) {
+				if _, ok := component[next]; !ok {
+					component[next] = id
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	result := []string{}
+	if len(sizes) < 2 {
+		return result
+	}
+	largest := 0
+	for id, size := range sizes {
+		if size > sizes[largest] {
+			largest = id
+		}
+	}
+	for _, node := range g.nodes {
+		if component[node] != largest {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// validateTopology rejects models with cycles, unreachable nodes or operators with unconnected input ports.
+func validateTopology(model lib.Model) error {
+	operators := map[string]bool{}
+	for _, cell := range model.Cells {
+		if cell.Type == NodeElementType {
+			operators[cell.Id] = true
+		}
+	}
+	var problems []lib.ModelProblem
+	for _, p := range analyzeModel(model).Problems {
+		if p.Code == lib.ProblemCycle || p.Code == lib.ProblemUnreachableNode || (p.Code == lib.ProblemUnusedInputPort && operators[p.CellId]) {
+			problems = append(problems, p)
+		}
+	}
+	return newModelError(MessageInvalidTopology, problems)
+}
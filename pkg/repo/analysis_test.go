@@ -0,0 +1,99 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+func TestAnalyzeModel(t *testing.T) {
+	model := lib.Model{Cells: []lib.Cell{
+		testNode("a"),
+		testNode("b", "in"),
+		testNode("c", "in", "extra"),
+		testNode("d", "in"),
+		testNode("e", "in"),
+		testLink("ab", "a", "b", "in"),
+		testLink("bc", "b", "c", "in"),
+		testLink("cd", "c", "d", "in"),
+		testLink("dc", "d", "c", "extra"),
+	}}
+	analysis := analyzeModel(model)
+	expected := []lib.ModelProblem{
+		{CellId: "c", Code: lib.ProblemCycle, Message: "part of cycle c -> d"},
+		{CellId: "d", Code: lib.ProblemCycle, Message: "part of cycle c -> d"},
+		{CellId: "e", Code: lib.ProblemUnreachableNode, Message: "not connected to the rest of the flow"},
+	}
+	unusedPort := lib.ModelProblem{CellId: "e", Port: "in", Code: lib.ProblemUnusedInputPort, Message: "input port 'in' is not connected"}
+	if !slices.Equal(analysis.Problems, append(slices.Clone(expected), unusedPort)) {
+		t.Fatalf("expected problems %+v, got %+v", expected, analysis.Problems)
+	}
+	if len(analysis.TopologicalOrder) != 0 || !slices.Equal(analysis.Sources, []string{"a", "e"}) || !slices.Equal(analysis.Sinks, []string{"e"}) {
+		t.Fatalf("unexpected analysis %+v", analysis)
+	}
+
+	err := validateTopology(model)
+	var me *lib.ModelError
+	expected = append(expected, unusedPort)
+	if !errors.As(err, &me) || !errors.As(err, new(*lib.InputError)) || !slices.Equal(me.Problems, expected) {
+		t.Fatalf("expected model error with problems %+v, got %v", expected, err)
+	}
+}
+
+func TestValidateTopologyInputPorts(t *testing.T) {
+	model := lib.Model{Cells: []lib.Cell{
+		testNode("a"),
+		testNode("b", "in", "unconnected"),
+		{Id: "c", Type: "senergy.ImportElement", InPorts: []string{"in"}, OutPorts: []string{"out"}},
+		testLink("ab", "a", "b", "in"),
+		testLink("bc", "b", "c", "in"),
+	}}
+	err := validateTopology(model)
+	var me *lib.ModelError
+	expected := []lib.ModelProblem{{CellId: "b", Port: "unconnected", Code: lib.ProblemUnusedInputPort, Message: "input port 'unconnected' is not connected"}}
+	if !errors.As(err, &me) || !slices.Equal(me.Problems, expected) {
+		t.Fatalf("expected model error with problems %+v, got %v", expected, err)
+	}
+
+	model.Cells = append(model.Cells, testLink("ab2", "a", "b", "unconnected"))
+	err = validateTopology(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStrictFlowAnalysis(t *testing.T) {
+	op := testOperator("strict")
+	env := newTestEnv(t, op)
+	env.cfg.StrictFlowAnalysis = true
+	operatorId := op.Id.Hex()
+	flow := lib.Flow{Name: "a", Model: lib.Model{Cells: []lib.Cell{
+		{Id: "a", Type: NodeElementType, OperatorId: &operatorId, InPorts: []string{"in"}},
+	}}}
+	_, err := env.repo.CreateFlow(t.Context(), flow, "owner", testToken("owner"))
+	var me *lib.ModelError
+	if !errors.As(err, &me) || len(me.Problems) != 1 || me.Problems[0].Code != lib.ProblemUnusedInputPort {
+		t.Fatalf("expected unconnected input port to be rejected, got %v", err)
+	}
+
+	env.cfg.StrictFlowAnalysis = false
+	createFlow(t, env, flow, "owner")
+}
@@ -18,6 +18,10 @@ package repo
 
 const PermV2InstanceTopic = "analytics-flows"
 
+const NodeElementType = "senergy.NodeElement"
+
 const (
-	MessageMissingRights = "requested instance nonexistent or missing rights"
+	MessageMissingRights   = "requested instance nonexistent or missing rights"
+	MessageInvalidModel    = "invalid flow model"
+	MessageInvalidTopology = "invalid flow topology"
 )
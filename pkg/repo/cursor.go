@@ -0,0 +1,93 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// flowCursor points behind the last flow of a page. It is handed to clients as opaque string and
+// is only valid for the sort field and order it was created with.
+type flowCursor struct {
+	Field string             `bson:"f"`
+	Order int64              `bson:"o"`
+	Value interface{}        `bson:"v,omitempty"`
+	Id    primitive.ObjectID `bson:"i"`
+}
+
+func newFlowCursor(field string, order int64, last lib.Flow) flowCursor {
+	c := flowCursor{Field: field, Order: order}
+	if last.Id != nil {
+		c.Id = *last.Id
+	}
+	switch field {
+	case "name":
+		c.Value = last.Name
+	case "dateCreated":
+		c.Value = last.DateCreated
+	case "dateUpdated":
+		c.Value = last.DateUpdated
+	case "score":
+		if last.Score != nil {
+			c.Value = *last.Score
+		}
+	}
+	return c
+}
+
+func decodeFlowCursor(s string, field string, order int64) (c flowCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, lib.NewInputError(errors.New("invalid cursor"))
+	}
+	err = bson.Unmarshal(b, &c)
+	if err != nil {
+		return c, lib.NewInputError(errors.New("invalid cursor"))
+	}
+	if c.Field != field || c.Order != order {
+		return c, lib.NewInputError(errors.New("cursor does not match sort order"))
+	}
+	return
+}
+
+func (c flowCursor) encode() (string, error) {
+	b, err := bson.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// filter matches all flows after the cursor position, using the id as tiebreaker for equal sort values.
+func (c flowCursor) filter() bson.M {
+	op := "$gt"
+	if c.Order < 0 {
+		op = "$lt"
+	}
+	if c.Field == "_id" {
+		return bson.M{"_id": bson.M{op: c.Id}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{c.Field: bson.M{op: c.Value}},
+		bson.M{c.Field: c.Value, "_id": bson.M{op: c.Id}},
+	}}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) InsertEvent(ctx context.Context, event lib.FlowEvent) (err error) {
+	defer metrics.ObserveMongoOperation("insert_event", time.Now())
+	_, err = r.outbox.InsertOne(ctx, event)
+	return
+}
+
+// PendingEvents returns the oldest events of the outbox which have not been published yet.
+func (r *MongoRepo) PendingEvents(ctx context.Context, limit int64) (events []lib.FlowEvent, err error) {
+	defer metrics.ObserveMongoOperation("pending_events", time.Now())
+	opt := options.Find().SetSort(bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := r.outbox.Find(ctx, bson.M{}, opt)
+	if err != nil {
+		return
+	}
+	events = []lib.FlowEvent{}
+	err = cursor.All(ctx, &events)
+	return
+}
+
+func (r *MongoRepo) RemoveEvents(ctx context.Context, ids []string) (err error) {
+	defer metrics.ObserveMongoOperation("remove_events", time.Now())
+	_, err = r.outbox.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return
+}
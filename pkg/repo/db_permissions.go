@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// flowPermissions manages the permissions-v2 resources of flows and bounds all calls by the http timeout.
+type flowPermissions struct {
+	perm        permV2Client.Client
+	httpTimeout time.Duration
+}
+
+func newFlowPermissions(cfg *config.Config, perm permV2Client.Client) (flowPermissions, error) {
+	_, err, _ := perm.SetTopic(permV2Client.InternalAdminToken, permV2Client.Topic{
+		Id: PermV2InstanceTopic,
+		DefaultPermissions: permV2Client.ResourcePermissions{
+			RolePermissions: map[string]permV2Model.PermissionsMap{
+				"admin": {
+					Read:         true,
+					Write:        true,
+					Execute:      true,
+					Administrate: true,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return flowPermissions{}, err
+	}
+	return flowPermissions{perm: perm, httpTimeout: cfg.HttpTimeout}, nil
+}
+
+func (r flowPermissions) checkPermission(ctx context.Context, id, auth string, permission permV2Client.Permission) error {
+	ok, err := callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() (bool, error) {
+		ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permission)
+		return ok, err
+	})
+	if err != nil {
+		return lib.NewExternalResourceError(err)
+	}
+	if !ok {
+		return lib.NewForbiddenError(errors.New(MessageMissingRights))
+	}
+	return nil
+}
+
+// setPermission may still be applied after a timeout error. Resources of flows which were not stored are removed by ValidateFlowPermissions.
+func (r flowPermissions) setPermission(ctx context.Context, id string, permissions permV2Client.ResourcePermissions) (permV2Client.ResourcePermissions, error) {
+	return callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() (permV2Client.ResourcePermissions, error) {
+		result, err, _ := r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, permissions)
+		return result, err
+	})
+}
+
+// removeResource may still be applied after a timeout error. The removal is repeated by the next purge, as the flow is only deleted afterwards.
+func (r flowPermissions) removeResource(ctx context.Context, id string) error {
+	_, err := callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() (struct{}, error) {
+		err, _ := r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+		return struct{}{}, err
+	})
+	return err
+}
+
+// PingPermissions checks if permissions-v2 is reachable by reading the flow topic.
+func (r flowPermissions) PingPermissions(ctx context.Context) error {
+	_, err := callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() (permV2Client.Topic, error) {
+		topic, err, _ := r.perm.GetTopic(permV2Client.InternalAdminToken, PermV2InstanceTopic)
+		return topic, err
+	})
+	return err
+}
+
+// accessibleIds lists the ids of all flows the user has the given permission for.
+func (r flowPermissions) accessibleIds(ctx context.Context, auth string, permission permV2Client.Permission) ([]string, error) {
+	return callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() ([]string, error) {
+		ids, err, _ := r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permission)
+		return ids, err
+	})
+}
+
+func (r flowPermissions) GetPermissions(ctx context.Context, id, _, auth string) (permissions lib.FlowPermissions, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	resource, err := callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() (permV2Client.Resource, error) {
+		resource, err, _ := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+		return resource, err
+	})
+	if err != nil {
+		return permissions, lib.NewExternalResourceError(err)
+	}
+	return toFlowPermissions(resource.ResourcePermissions), nil
+}
+
+// SetPermissions replaces all permissions of a flow. At least one user has to keep the administrate permission.
+func (r flowPermissions) SetPermissions(ctx context.Context, id string, permissions lib.FlowPermissions, _, auth string) (result lib.FlowPermissions, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	resourcePermissions := toResourcePermissions(permissions)
+	if !resourcePermissions.Valid() {
+		return result, lib.NewInputError(errors.New("at least one user needs the administrate permission"))
+	}
+	resourcePermissions, err = r.setPermission(ctx, id, resourcePermissions)
+	if err != nil {
+		return result, lib.NewExternalResourceError(err)
+	}
+	return toFlowPermissions(resourcePermissions), nil
+}
+
+func toFlowPermissions(permissions permV2Client.ResourcePermissions) lib.FlowPermissions {
+	convert := func(in map[string]permV2Client.PermissionsMap) map[string]lib.PermissionsMap {
+		out := make(map[string]lib.PermissionsMap, len(in))
+		for key, value := range in {
+			out[key] = lib.PermissionsMap(value)
+		}
+		return out
+	}
+	return lib.FlowPermissions{
+		UserPermissions:  convert(permissions.UserPermissions),
+		GroupPermissions: convert(permissions.GroupPermissions),
+		RolePermissions:  convert(permissions.RolePermissions),
+	}
+}
+
+func toResourcePermissions(permissions lib.FlowPermissions) permV2Client.ResourcePermissions {
+	convert := func(in map[string]lib.PermissionsMap) map[string]permV2Client.PermissionsMap {
+		out := make(map[string]permV2Client.PermissionsMap, len(in))
+		for key, value := range in {
+			out[key] = permV2Client.PermissionsMap(value)
+		}
+		return out
+	}
+	return permV2Client.ResourcePermissions{
+		UserPermissions:  convert(permissions.UserPermissions),
+		GroupPermissions: convert(permissions.GroupPermissions),
+		RolePermissions:  convert(permissions.RolePermissions),
+	}
+}
@@ -21,13 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"maps"
-	"regexp"
-	"slices"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
 	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
@@ -38,45 +36,120 @@ import (
 )
 
 type FlowRepository interface {
-	InsertFlow(flow lib.Flow) (id string, err error)
-	UpdateFlow(id string, flow lib.Flow, userId string, auth string) (err error)
-	DeleteFlow(id string, userId string, admin bool, auth string) (err error)
-	All(userId string, admin bool, args map[string][]string, auth string) (response lib.FlowsResponse, err error)
-	FindFlow(id, userId, auth string) (flow lib.Flow, err error)
-	GetOperatorFlowMapping() ([]lib.OperatorFlowCount, error)
+	InsertFlow(ctx context.Context, flow lib.Flow) (id string, revision int64, err error)
+	UpdateFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64, userId string, auth string) (revision int64, err error)
+	DeleteFlow(ctx context.Context, id string, userId string, admin bool, auth string) (err error)
+	All(ctx context.Context, userId string, admin bool, args map[string][]string, auth string) (response lib.FlowsResponse, err error)
+	FindFlow(ctx context.Context, id, userId, auth string) (flow lib.Flow, err error)
+	GetOperatorFlowMapping(ctx context.Context) ([]lib.OperatorFlowCount, error)
+	AllTags(ctx context.Context, userId string, auth string) (tags []lib.TagCount, err error)
+	AllDeleted(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error)
+	RestoreFlow(ctx context.Context, id string, userId string, auth string) (err error)
+	PurgeFlows(ctx context.Context, deletedBefore time.Time) (count int, err error)
+	AllRevisions(ctx context.Context, id, userId string, args map[string][]string, auth string) (response lib.FlowRevisionsResponse, err error)
+	FindRevision(ctx context.Context, id string, revision int64, userId, auth string) (flowRevision lib.FlowRevision, err error)
+	GetPermissions(ctx context.Context, id, userId, auth string) (permissions lib.FlowPermissions, err error)
+	SetPermissions(ctx context.Context, id string, permissions lib.FlowPermissions, userId, auth string) (result lib.FlowPermissions, err error)
+	CountFlows(ctx context.Context) (count int64, err error)
+	Ping(ctx context.Context) error
+	// WithTransaction runs fn so that all writes of the repository made with the passed context are applied together or not at all,
+	// as far as the storage supports it.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	PingPermissions(ctx context.Context) error
+	InsertEvent(ctx context.Context, event lib.FlowEvent) (err error)
+	PendingEvents(ctx context.Context, limit int64) (events []lib.FlowEvent, err error)
+	RemoveEvents(ctx context.Context, ids []string) (err error)
 }
 
 type MongoRepo struct {
-	perm permV2Client.Client
+	flowPermissions
+	flows          *mongo.Collection
+	revisions      *mongo.Collection
+	migrations     *mongo.Collection
+	outbox         *mongo.Collection
+	transactions   bool
+	revisionLimit  int
+	revisionMaxAge time.Duration
 }
 
-func NewMongoRepo(perm permV2Client.Client) *MongoRepo {
-	_, err, _ := perm.SetTopic(permV2Client.InternalAdminToken, permV2Client.Topic{
-		Id: PermV2InstanceTopic,
-		DefaultPermissions: permV2Client.ResourcePermissions{
-			RolePermissions: map[string]permV2Model.PermissionsMap{
-				"admin": {
-					Read:         true,
-					Write:        true,
-					Execute:      true,
-					Administrate: true,
-				},
-			},
-		},
-	})
+func NewMongoRepo(ctx context.Context, cfg *config.Config, client *mongo.Client, perm permV2Client.Client) (*MongoRepo, error) {
+	permissions, err := newFlowPermissions(cfg, perm)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := supportsTransactions(ctx, client)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	return &MongoRepo{perm: perm}
+	if !transactions {
+		util.Logger.Warn("mongodb is neither a replica set nor a sharded cluster, flow changes are written without transactions")
+	}
+	db := client.Database(cfg.MongoDatabase)
+	return &MongoRepo{
+		flowPermissions: permissions,
+		flows:           db.Collection(cfg.MongoFlowsCollection),
+		revisions:       db.Collection(cfg.MongoRevisionsCollection),
+		migrations:      db.Collection(cfg.MongoMigrationsCollection),
+		outbox:          db.Collection(cfg.MongoOutboxCollection),
+		transactions:    transactions,
+		revisionLimit:   cfg.RevisionLimit,
+		revisionMaxAge:  cfg.RevisionMaxAge,
+	}, nil
 }
 
-func (r *MongoRepo) validateFlowPermissions() (err error) {
-	util.Logger.Debug("validate flows permissions")
-	resp, err := r.All("", true, map[string][]string{}, "")
+// supportsTransactions reports if the deployment is a replica set or a sharded cluster, standalone servers have no transactions.
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
 	if err != nil {
-		return
+		return false, err
 	}
-	permResources, err, _ := r.perm.ListResourcesWithAdminPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{})
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+// WithTransaction runs fn in a session transaction, which is committed if fn succeeds. Failed transactions are not retried,
+// because fn may include calls to other services. Without transaction support fn runs directly.
+func (r *MongoRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !r.transactions || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	session, err := r.flows.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+	err = session.StartTransaction()
+	if err != nil {
+		return err
+	}
+	err = fn(mongo.NewSessionContext(ctx, session))
+	if err != nil {
+		_ = session.AbortTransaction(context.WithoutCancel(ctx))
+		return err
+	}
+	return session.CommitTransaction(context.WithoutCancel(ctx))
+}
+
+// saveRevisionOrLog saves a revision as part of a flow change. Outside a transaction the change is already stored,
+// so a failed revision is only logged instead of failing the request for a change which was applied.
+func (r *MongoRepo) saveRevisionOrLog(ctx context.Context, id string, flow lib.Flow, author string, previous *lib.Flow) (int64, error) {
+	revision, err := r.saveRevision(ctx, id, flow, author, previous)
+	if err != nil && mongo.SessionFromContext(ctx) == nil {
+		util.Logger.Error("error saving flow revision", "error", err, "flow", id)
+		return 0, nil
+	}
+	return revision, err
+}
+
+func (r *MongoRepo) ValidateFlowPermissions(ctx context.Context) (err error) {
+	util.Logger.Debug("validate flows permissions")
+	permResources, err := callWithTimeout(ctx, r.httpTimeout, metrics.TargetPermissionsV2, func() ([]permV2Client.Resource, error) {
+		permResources, err, _ := r.perm.ListResourcesWithAdminPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{})
+		return permResources, err
+	})
 	if err != nil {
 		return
 	}
@@ -85,15 +158,28 @@ func (r *MongoRepo) validateFlowPermissions() (err error) {
 		permResourceMap[permResource.Id] = permResource
 	}
 
-	dbIds := []string{}
-	for _, flow := range resp.Flows {
+	// flows are streamed, only their ids and owners are needed
+	cur, err := r.flows.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1, "userId": 1}))
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	dbIds := map[string]bool{}
+	for cur.Next(ctx) {
+		var flow lib.Flow
+		err = cur.Decode(&flow)
+		if err != nil {
+			return
+		}
 		permissions := permV2Client.ResourcePermissions{
 			UserPermissions:  map[string]permV2Client.PermissionsMap{},
 			GroupPermissions: map[string]permV2Client.PermissionsMap{},
 			RolePermissions:  map[string]permV2Model.PermissionsMap{},
 		}
 		flowId := flow.Id.Hex()
-		dbIds = append(dbIds, flowId)
+		dbIds[flowId] = true
 		resource, ok := permResourceMap[flowId]
 		if ok {
 			permissions.UserPermissions = resource.ResourcePermissions.UserPermissions
@@ -102,16 +188,20 @@ func (r *MongoRepo) validateFlowPermissions() (err error) {
 		}
 		SetDefaultPermissions(flow, permissions)
 
-		_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, flowId, permissions)
+		_, err = r.setPermission(ctx, flowId, permissions)
 		if err != nil {
 			return
 		}
 	}
+	err = cur.Err()
+	if err != nil {
+		return
+	}
 	permResourceIds := maps.Keys(permResourceMap)
 
 	for permResouceId := range permResourceIds {
-		if !slices.Contains(dbIds, permResouceId) {
-			err, _ = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, permResouceId)
+		if !dbIds[permResouceId] {
+			err = r.removeResource(ctx, permResouceId)
 			if err != nil {
 				return
 			}
@@ -121,7 +211,11 @@ func (r *MongoRepo) validateFlowPermissions() (err error) {
 	return
 }
 
-func (r *MongoRepo) InsertFlow(flow lib.Flow) (id string, err error) {
+func (r *MongoRepo) InsertFlow(ctx context.Context, flow lib.Flow) (id string, revision int64, err error) {
+	flow.Summary = nil
+	flow.Score = nil
+	flow.DateDeleted = nil
+	flow.Tags = normalizeTags(flow.Tags)
 	flow.DateCreated = time.Now()
 	flow.DateUpdated = time.Now()
 	permissions := permV2Client.ResourcePermissions{
@@ -130,175 +224,191 @@ func (r *MongoRepo) InsertFlow(flow lib.Flow) (id string, err error) {
 		RolePermissions:  map[string]permV2Model.PermissionsMap{},
 	}
 	SetDefaultPermissions(flow, permissions)
-	result, err := Mongo().InsertOne(CTX, flow)
+	start := time.Now()
+	result, err := r.flows.InsertOne(ctx, flow)
+	metrics.ObserveMongoOperation("insert_flow", start)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	id = result.InsertedID.(primitive.ObjectID).Hex()
-	_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, permissions)
+	_, err = r.setPermission(ctx, id, permissions)
 	if err != nil {
 		err = lib.NewExternalResourceError(err)
+		return
 	}
+	revision, err = r.saveRevisionOrLog(ctx, id, flow, flow.UserId, nil)
 	return
 }
 
-func (r *MongoRepo) UpdateFlow(id string, flow lib.Flow, _ string, auth string) (err error) {
-	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Write)
+func (r *MongoRepo) UpdateFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64, userId string, auth string) (revision int64, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Write)
 	if err != nil {
-		return lib.NewExternalResourceError(err)
+		return
 	}
-	if !ok {
-		return lib.NewForbiddenError(errors.New(MessageMissingRights))
+	flow, previous, err := r.replaceFlow(ctx, id, flow, expectedVersion)
+	if err != nil {
+		return
 	}
+	return r.saveRevisionOrLog(ctx, id, flow, userId, &previous)
+}
+
+// replaceFlow stores the next version of a flow and returns it together with the replaced version.
+func (r *MongoRepo) replaceFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64) (_ lib.Flow, previous lib.Flow, err error) {
+	defer metrics.ObserveMongoOperation("update_flow", time.Now())
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return
 	}
+	var current lib.Flow
+	err = r.flows.FindOne(ctx, bson.M{"_id": objID, "dateDeleted": bson.M{"$exists": false}}).Decode(&current)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return flow, previous, lib.NewNotFoundError(errors.New("could not find flow " + id))
+	}
+	if err != nil {
+		return
+	}
+	if expectedVersion != nil && *expectedVersion != current.Version {
+		return flow, previous, lib.NewPreconditionFailedError(fmt.Errorf("flow %s has version %d, expected %d", id, current.Version, *expectedVersion))
+	}
+	// ownership and creation date are not part of an update
+	flow.Id = current.Id
+	flow.UserId = current.UserId
+	flow.DateCreated = current.DateCreated
 	flow.DateUpdated = time.Now()
-	res, err := Mongo().ReplaceOne(CTX, bson.M{"_id": objID}, flow)
+	flow.DateDeleted = current.DateDeleted
+	flow.Version = current.Version + 1
+	flow.Tags = normalizeTags(flow.Tags)
+	flow.Summary = nil
+	flow.Score = nil
+	filter := bson.M{"_id": objID, "dateDeleted": bson.M{"$exists": false}, "$or": versionFilter(current.Version)}
+	err = r.flows.FindOneAndReplace(ctx, filter, flow).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return flow, previous, lib.NewPreconditionFailedError(fmt.Errorf("flow %s was modified concurrently", id))
+	}
+	return flow, previous, err
+}
+
+// versionFilter matches the given flow version. Flows stored before versioning was introduced have no version field and count as version 0.
+func versionFilter(version int64) bson.A {
+	filter := bson.A{bson.M{"version": version}}
+	if version == 0 {
+		filter = append(filter, bson.M{"version": bson.M{"$exists": false}})
+	}
+	return filter
+}
+
+// DeleteFlow moves a flow to the trash. Trashed flows keep their permissions and revisions until they are purged.
+func (r *MongoRepo) DeleteFlow(ctx context.Context, id string, _ string, _ bool, auth string) (err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	defer metrics.ObserveMongoOperation("delete_flow", time.Now())
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return
+	}
+	res, err := r.flows.UpdateOne(ctx, bson.M{"_id": objID, "dateDeleted": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"dateDeleted": time.Now()}})
+	if err != nil {
+		return
+	}
 	if res.MatchedCount == 0 {
 		return lib.NewNotFoundError(errors.New("could not find flow " + id))
 	}
 	return
 }
 
-func (r *MongoRepo) DeleteFlow(id string, _ string, _ bool, auth string) (err error) {
-	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Administrate)
+func (r *MongoRepo) RestoreFlow(ctx context.Context, id string, _ string, auth string) (err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Administrate)
 	if err != nil {
-		return err
-	}
-	if !ok {
-		return errors.New(MessageMissingRights)
+		return
 	}
-
+	defer metrics.ObserveMongoOperation("restore_flow", time.Now())
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return
 	}
-	req := bson.M{"_id": objID}
-	res := Mongo().FindOneAndDelete(CTX, req)
-	if res.Err() != nil {
-		return res.Err()
+	res, err := r.flows.UpdateOne(ctx, bson.M{"_id": objID, "dateDeleted": bson.M{"$exists": true}}, bson.M{"$unset": bson.M{"dateDeleted": ""}})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return lib.NewNotFoundError(errors.New("could not find deleted flow " + id))
 	}
-	err, _ = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
 	return
 }
 
-func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
-	opt := options.Find()
-	for arg, value := range args {
-		if len(value) == 0 {
-			continue
+// PurgeFlows permanently removes flows, their permissions and revisions, which were deleted before the given time.
+func (r *MongoRepo) PurgeFlows(ctx context.Context, deletedBefore time.Time) (count int, err error) {
+	flows, err := r.trashedFlows(ctx, deletedBefore)
+	if err != nil {
+		return
+	}
+	for _, flow := range flows {
+		id := flow.Id.Hex()
+		err = r.removeResource(ctx, id)
+		if err != nil {
+			return count, lib.NewExternalResourceError(err)
 		}
-
-		switch arg {
-		case "sort":
-			sortFields := []string{"name", "dateCreated", "dateUpdated"}
-			ord := strings.SplitN(value[0], ":", 2)
-			if len(ord) == 2 {
-				field, dir := ord[0], ord[1]
-				if slices.Contains(sortFields, field) {
-					order := int64(1)
-					if dir == "desc" {
-						order = -1
-					}
-					opt.SetSort(bson.M{field: order})
-				}
-			}
-		case "limit":
-			var limit int64
-			limit, err = strconv.ParseInt(value[0], 10, 64)
-			if err != nil {
-				return
-			}
-			if limit > 0 {
-				opt.SetLimit(limit)
-			}
-		case "offset":
-			var skip int64
-			skip, err = strconv.ParseInt(value[0], 10, 64)
-			if err != nil {
-				return
-			}
-			if skip > 0 {
-				opt.SetSkip(skip)
-			}
+		err = r.purgeFlow(ctx, flow)
+		if err != nil {
+			return
 		}
+		count++
+	}
+	return
+}
+
+func (r *MongoRepo) trashedFlows(ctx context.Context, deletedBefore time.Time) (flows []lib.Flow, err error) {
+	defer metrics.ObserveMongoOperation("find_trashed_flows", time.Now())
+	cur, err := r.flows.Find(ctx, bson.M{"dateDeleted": bson.M{"$lt": deletedBefore}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return
 	}
+	err = cur.All(ctx, &flows)
+	return
+}
 
-	andFilters := bson.A{}
+func (r *MongoRepo) purgeFlow(ctx context.Context, flow lib.Flow) (err error) {
+	defer metrics.ObserveMongoOperation("purge_flow", time.Now())
+	err = r.deleteRevisions(ctx, flow.Id.Hex())
+	if err != nil {
+		return
+	}
+	_, err = r.flows.DeleteOne(ctx, bson.M{"_id": flow.Id})
+	return
+}
+
+func (r *MongoRepo) All(ctx context.Context, userId string, admin bool, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
+	return r.list(ctx, "list_flows", userId, admin, args, auth, permV2Client.Read, bson.A{bson.M{"dateDeleted": bson.M{"$exists": false}}})
+}
+
+func (r *MongoRepo) AllDeleted(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
+	return r.list(ctx, "list_deleted_flows", userId, false, args, auth, permV2Client.Administrate, bson.A{bson.M{"dateDeleted": bson.M{"$exists": true}}})
+}
+
+func (r *MongoRepo) list(ctx context.Context, operation string, userId string, admin bool, args map[string][]string, auth string, permission permV2Client.Permission, andFilters bson.A) (response lib.FlowsResponse, err error) {
+	opts, err := parseListOptions(args)
+	if err != nil {
+		return
+	}
 
-	ids := []primitive.ObjectID{}
-	var stringIds []string
 	if !admin {
-		stringIds, err, _ = r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+		var accessFilter bson.M
+		accessFilter, err = r.accessFilter(ctx, userId, auth, permission)
 		if err != nil {
 			return
 		}
-		for _, id := range stringIds {
-			objID, err := primitive.ObjectIDFromHex(id)
-			if err != nil {
-				return lib.FlowsResponse{}, err
-			}
-			ids = append(ids, objID)
-		}
-
-		andFilters = append(andFilters, bson.M{
-			"$or": bson.A{
-				bson.M{"_id": bson.M{"$in": ids}},
-				bson.M{"userid": userId},
-			},
-		})
+		andFilters = append(andFilters, accessFilter)
 	}
-	if val, ok := args["search"]; ok && len(val) > 0 {
-		pattern := regexp.QuoteMeta(val[0])
+	defer metrics.ObserveMongoOperation(operation, time.Now())
+	if opts.search != "" {
 		andFilters = append(andFilters, bson.M{
-			"name": bson.M{
-				"$regex":   pattern,
-				"$options": "i",
-			},
+			"$text": bson.M{"$search": opts.search},
 		})
 	}
-
-	if vals, ok := args["filter"]; ok {
-		for _, raw := range vals {
-			for _, f := range strings.Split(raw, "|") {
-
-				parts := strings.SplitN(f, ":", 2)
-				if len(parts) != 2 {
-					continue
-				}
-
-				key := parts[0]
-				values := strings.Split(parts[1], ",")
-				if len(values) == 0 {
-					continue
-				}
-
-				switch key {
-
-				case "operator":
-					andFilters = append(andFilters, bson.M{
-						"model.cells": bson.M{
-							"$elemMatch": bson.M{
-								"type":       "senergy.NodeElement",
-								"operatorid": bson.M{"$in": values},
-							},
-						},
-					})
-
-				default:
-					fieldMap := map[string]string{}
-					field, exists := fieldMap[key]
-					if !exists {
-						continue
-					}
-					andFilters = append(andFilters, bson.M{
-						field: bson.M{"$in": values},
-					})
-				}
-			}
-		}
+	for _, filter := range opts.filters {
+		andFilters = append(andFilters, filterQuery(filter))
 	}
 
 	req := bson.M{}
@@ -306,61 +416,211 @@ func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, aut
 		req["$and"] = andFilters
 	}
 
-	var cur *mongo.Cursor
+	page := bson.A{}
+	if opts.cursor != nil {
+		page = append(page, bson.M{"$match": opts.cursor.filter()})
+	}
+	if opts.sortField != "" {
+		sort := bson.D{{Key: opts.sortField, Value: opts.sortOrder}}
+		if opts.cursorMode && opts.sortField != "_id" {
+			sort = append(sort, bson.E{Key: "_id", Value: opts.sortOrder})
+		}
+		page = append(page, bson.M{"$sort": sort})
+	}
+	if opts.skip > 0 {
+		page = append(page, bson.M{"$skip": opts.skip})
+	}
+	if limit := opts.pageLimit(); limit > 0 {
+		page = append(page, bson.M{"$limit": limit})
+	}
+	if opts.summary {
+		page = append(page, summaryStage())
+	}
+	if opts.projection != nil {
+		projection := maps.Clone(opts.projection)
+		if opts.summary {
+			projection["summary"] = 1
+		}
+		if opts.sortField != "" && opts.sortField != "_id" {
+			projection[opts.sortField] = 1
+		}
+		page = append(page, bson.M{"$project": projection})
+	} else if opts.summary {
+		page = append(page, bson.M{"$project": bson.M{"model": 0}})
+	}
 
-	cur, err = Mongo().Find(CTX, req, opt)
-	if err != nil {
-		return
+	// a limited page and its total are queried in one aggregation. Unlimited pages are streamed and counted separately,
+	// because the single result document of $facet is bound by the 16 MB document size limit.
+	facet := opts.countTotal && opts.pageLimit() > 0
+	pipeline := bson.A{bson.M{"$match": req}}
+	if opts.search != "" {
+		pipeline = append(pipeline, bson.M{"$addFields": bson.M{"score": bson.M{"$meta": "textScore"}}})
+	}
+	if facet {
+		if len(page) == 0 {
+			page = append(page, bson.M{"$match": bson.M{}})
+		}
+		pipeline = append(pipeline, bson.M{"$facet": bson.M{
+			"flows": page,
+			"total": bson.A{bson.M{"$count": "count"}},
+		}})
+	} else {
+		pipeline = append(pipeline, page...)
 	}
 
-	response.Total, err = Mongo().CountDocuments(CTX, req)
+	cur, err := r.flows.Aggregate(ctx, pipeline)
 	if err != nil {
 		return
 	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+
 	response.Flows = make([]lib.Flow, 0)
+	if facet {
+		var result []struct {
+			Flows []lib.Flow `bson:"flows"`
+			Total []struct {
+				Count int64 `bson:"count"`
+			} `bson:"total"`
+		}
+		err = cur.All(ctx, &result)
+		if err != nil {
+			return lib.FlowsResponse{}, err
+		}
+		if len(result) > 0 {
+			response.Flows = append(response.Flows, result[0].Flows...)
+			if len(result[0].Total) > 0 {
+				response.Total = result[0].Total[0].Count
+			}
+		}
+	} else {
+		err = cur.All(ctx, &response.Flows)
+		if err != nil {
+			return lib.FlowsResponse{}, err
+		}
+		response.Total = -1
+		if opts.countTotal {
+			response.Total, err = r.flows.CountDocuments(ctx, req)
+			if err != nil {
+				return lib.FlowsResponse{}, err
+			}
+		}
+	}
+	err = opts.finishPage(&response)
+	return
+}
 
-	err = cur.All(CTX, &response.Flows)
-	defer func(cur *mongo.Cursor, ctx context.Context) {
-		err := cur.Close(ctx)
+func filterQuery(filter listFilter) bson.M {
+	switch filter.key {
+	case "operator":
+		return bson.M{
+			"model.cells": bson.M{
+				"$elemMatch": bson.M{
+					"type":       NodeElementType,
+					"operatorId": bson.M{"$in": filter.values},
+				},
+			},
+		}
+	case "deploymentType":
+		return bson.M{
+			"model.cells": bson.M{
+				"$elemMatch": bson.M{
+					"type":           NodeElementType,
+					"deploymentType": bson.M{"$in": filter.values},
+				},
+			},
+		}
+	case "dateCreated", "dateUpdated":
+		return bson.M{filter.key: filter.dateRange}
+	case "tag":
+		return bson.M{"tags": bson.M{"$in": filter.values}}
+	default:
+		return bson.M{"userId": bson.M{"$in": filter.values}}
+	}
+}
+
+// accessFilter matches all flows the user has the given permission for.
+func (r *MongoRepo) accessFilter(ctx context.Context, userId string, auth string, permission permV2Client.Permission) (filter bson.M, err error) {
+	stringIds, err := r.accessibleIds(ctx, auth, permission)
+	if err != nil {
+		return
+	}
+	ids := []primitive.ObjectID{}
+	for _, id := range stringIds {
+		objID, err := primitive.ObjectIDFromHex(id)
 		if err != nil {
-			return
+			return nil, err
 		}
-	}(cur, CTX)
+		ids = append(ids, objID)
+	}
+	return bson.M{
+		"$or": bson.A{
+			bson.M{"_id": bson.M{"$in": ids}},
+			bson.M{"userId": userId},
+		},
+	}, nil
+}
 
+func (r *MongoRepo) AllTags(ctx context.Context, userId string, auth string) (tags []lib.TagCount, err error) {
+	accessFilter, err := r.accessFilter(ctx, userId, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	defer metrics.ObserveMongoOperation("list_tags", time.Now())
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$and": bson.A{accessFilter, bson.M{"dateDeleted": bson.M{"$exists": false}}}}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+	cursor, err := r.flows.Aggregate(ctx, pipeline)
 	if err != nil {
-		return lib.FlowsResponse{}, err
+		return
 	}
+	tags = make([]lib.TagCount, 0)
+	err = cursor.All(ctx, &tags)
 	return
 }
 
-func (r *MongoRepo) FindFlow(id, _, auth string) (flow lib.Flow, err error) {
+func (r *MongoRepo) FindFlow(ctx context.Context, id, _, auth string) (flow lib.Flow, err error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return
 	}
 
-	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Read)
+	err = r.checkPermission(ctx, id, auth, permV2Client.Read)
 	if err != nil {
-		return flow, lib.NewExternalResourceError(err)
-	}
-	if !ok {
-		return flow, lib.NewForbiddenError(errors.New(MessageMissingRights))
+		return
 	}
-	err = Mongo().FindOne(CTX, bson.M{"_id": objID}).Decode(&flow)
+	defer metrics.ObserveMongoOperation("find_flow", time.Now())
+	err = r.flows.FindOne(ctx, bson.M{"_id": objID, "dateDeleted": bson.M{"$exists": false}}).Decode(&flow)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (r *MongoRepo) GetOperatorFlowMapping() ([]lib.OperatorFlowCount, error) {
+// CountFlows counts all flows which are not deleted, regardless of permissions.
+func (r *MongoRepo) CountFlows(ctx context.Context) (count int64, err error) {
+	defer metrics.ObserveMongoOperation("count_flows", time.Now())
+	return r.flows.CountDocuments(ctx, bson.M{"dateDeleted": bson.M{"$exists": false}})
+}
+
+func (r *MongoRepo) Ping(ctx context.Context) error {
+	return r.flows.Database().Client().Ping(ctx, nil)
+}
+
+func (r *MongoRepo) GetOperatorFlowMapping(ctx context.Context) ([]lib.OperatorFlowCount, error) {
+	defer metrics.ObserveMongoOperation("operator_flow_mapping", time.Now())
 	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"dateDeleted": bson.M{"$exists": false}}}},
 		{{"$unwind", "$model.cells"}},
-		{{"$match", bson.D{{"model.cells.type", "senergy.NodeElement"}}}},
+		{{"$match", bson.D{{"model.cells.type", NodeElementType}}}},
 		{{"$group", bson.D{
 			{"_id", bson.D{
 				{"flowId", "$_id"},
-				{"operatorId", "$model.cells.operatorid"},
+				{"operatorId", "$model.cells.operatorId"},
 			}},
 			{"count", bson.D{{"$sum", 1}}},
 		}}},
@@ -373,7 +633,7 @@ func (r *MongoRepo) GetOperatorFlowMapping() ([]lib.OperatorFlowCount, error) {
 		}}},
 	}
 
-	cursor, err := Mongo().Aggregate(CTX, pipeline)
+	cursor, err := r.flows.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -382,10 +642,10 @@ func (r *MongoRepo) GetOperatorFlowMapping() ([]lib.OperatorFlowCount, error) {
 		if err != nil {
 			return
 		}
-	}(cursor, CTX)
+	}(cursor, ctx)
 
 	var results []lib.OperatorFlowCount
-	if err = cursor.All(CTX, &results); err != nil {
+	if err = cursor.All(ctx, &results); err != nil {
 		return nil, err
 	}
 
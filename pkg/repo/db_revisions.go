@@ -0,0 +1,159 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// saveRevision stores the given flow state as the next revision of the flow, returns its number and prunes revisions exceeding the configured retention.
+// Flows stored before revisions were introduced get their previous state recorded first, so no model is lost on the first update.
+func (r *MongoRepo) saveRevision(ctx context.Context, id string, flow lib.Flow, author string, previous *lib.Flow) (latest int64, err error) {
+	defer metrics.ObserveMongoOperation("save_revision", time.Now())
+	latest, err = r.latestRevision(ctx, id)
+	if err != nil {
+		return
+	}
+	if latest == 0 && previous != nil {
+		latest++
+		err = r.insertRevision(ctx, id, latest, *previous, previous.UserId, previous.DateUpdated)
+		if err != nil {
+			return
+		}
+	}
+	latest++
+	err = r.insertRevision(ctx, id, latest, flow, author, flow.DateUpdated)
+	if err != nil {
+		return
+	}
+	err = r.pruneRevisions(ctx, id, latest)
+	return
+}
+
+func (r *MongoRepo) insertRevision(ctx context.Context, id string, revision int64, flow lib.Flow, author string, timestamp time.Time) (err error) {
+	model := flow.Model
+	_, err = r.revisions.InsertOne(ctx, lib.FlowRevision{
+		FlowId:      id,
+		Revision:    revision,
+		Name:        flow.Name,
+		Description: flow.Description,
+		Model:       &model,
+		Author:      author,
+		Timestamp:   timestamp,
+	})
+	return
+}
+
+func (r *MongoRepo) latestRevision(ctx context.Context, id string) (latest int64, err error) {
+	var revision lib.FlowRevision
+	opt := options.FindOne().SetSort(bson.M{"revision": -1}).SetProjection(bson.M{"revision": 1})
+	err = r.revisions.FindOne(ctx, bson.M{"flowId": id}, opt).Decode(&revision)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return revision.Revision, err
+}
+
+// pruneRevisions removes revisions beyond the configured count and age limits. The latest revision is always kept.
+func (r *MongoRepo) pruneRevisions(ctx context.Context, id string, latest int64) (err error) {
+	if r.revisionLimit > 0 && latest > int64(r.revisionLimit) {
+		_, err = r.revisions.DeleteMany(ctx, bson.M{
+			"flowId":   id,
+			"revision": bson.M{"$lte": latest - int64(r.revisionLimit)},
+		})
+		if err != nil {
+			return
+		}
+	}
+	if r.revisionMaxAge > 0 {
+		_, err = r.revisions.DeleteMany(ctx, bson.M{
+			"flowId":    id,
+			"revision":  bson.M{"$lt": latest},
+			"timestamp": bson.M{"$lt": time.Now().Add(-r.revisionMaxAge)},
+		})
+	}
+	return
+}
+
+func (r *MongoRepo) deleteRevisions(ctx context.Context, id string) (err error) {
+	_, err = r.revisions.DeleteMany(ctx, bson.M{"flowId": id})
+	return
+}
+
+func (r *MongoRepo) AllRevisions(ctx context.Context, id, _ string, args map[string][]string, auth string) (response lib.FlowRevisionsResponse, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	defer metrics.ObserveMongoOperation("list_revisions", time.Now())
+	opt := options.Find().SetSort(bson.M{"revision": -1}).SetProjection(bson.M{"model": 0})
+	if value, ok := args["limit"]; ok && len(value) > 0 {
+		var limit int64
+		limit, err = strconv.ParseInt(value[0], 10, 64)
+		if err != nil {
+			return response, lib.NewInputError(errors.New("invalid limit " + value[0]))
+		}
+		if limit > 0 {
+			opt.SetLimit(limit)
+		}
+	}
+	if value, ok := args["offset"]; ok && len(value) > 0 {
+		var skip int64
+		skip, err = strconv.ParseInt(value[0], 10, 64)
+		if err != nil {
+			return response, lib.NewInputError(errors.New("invalid offset " + value[0]))
+		}
+		if skip > 0 {
+			opt.SetSkip(skip)
+		}
+	}
+	req := bson.M{"flowId": id}
+	cur, err := r.revisions.Find(ctx, req, opt)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	response.Revisions = make([]lib.FlowRevision, 0)
+	err = cur.All(ctx, &response.Revisions)
+	if err != nil {
+		return lib.FlowRevisionsResponse{}, err
+	}
+	response.Total, err = r.revisions.CountDocuments(ctx, req)
+	return
+}
+
+func (r *MongoRepo) FindRevision(ctx context.Context, id string, revision int64, _, auth string) (flowRevision lib.FlowRevision, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	defer metrics.ObserveMongoOperation("find_revision", time.Now())
+	err = r.revisions.FindOne(ctx, bson.M{"flowId": id, "revision": revision}).Decode(&flowRevision)
+	return
+}
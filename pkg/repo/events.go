@@ -0,0 +1,107 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordEvent stores a flow event in the outbox, from where RunEventRelay publishes it. It has to run in the
+// transaction of the change, so the change fails if its event can not be stored.
+func (r *Repo) recordEvent(ctx context.Context, eventType lib.FlowEventType, flowId, userId string, revision int64) error {
+	if r.events == nil {
+		return nil
+	}
+	return r.dbRepo.InsertEvent(ctx, lib.FlowEvent{
+		Id:       primitive.NewObjectID().Hex(),
+		Version:  lib.FlowEventVersion,
+		Type:     eventType,
+		FlowId:   flowId,
+		UserId:   userId,
+		Revision: revision,
+		Time:     time.Now().UTC(),
+	})
+}
+
+// notifyEventRelay wakes up RunEventRelay after a change has been committed.
+func (r *Repo) notifyEventRelay() {
+	select {
+	case r.eventsNotify <- struct{}{}:
+	default:
+	}
+}
+
+// RunEventRelay publishes the events of the outbox and removes them once they are delivered, until ctx is done.
+// The outbox is checked after each recorded event and periodically, so events are retried if the broker was unavailable.
+// Events may be published more than once if several instances relay concurrently or removal fails.
+func (r *Repo) RunEventRelay(ctx context.Context) {
+	if r.events == nil || r.cfg.OutboxInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.OutboxInterval)
+	defer ticker.Stop()
+	for {
+		err := r.relayEvents(ctx)
+		if err != nil {
+			util.Logger.Error("error relaying flow events", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.eventsNotify:
+		}
+	}
+}
+
+func (r *Repo) relayEvents(ctx context.Context) error {
+	for {
+		events, err := r.dbRepo.PendingEvents(ctx, r.cfg.OutboxBatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		err = r.publish(ctx, events)
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.Id)
+		}
+		err = r.dbRepo.RemoveEvents(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if int64(len(events)) < r.cfg.OutboxBatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *Repo) publish(ctx context.Context, events []lib.FlowEvent) error {
+	if r.cfg.HttpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.HttpTimeout)
+		defer cancel()
+	}
+	return r.events.Publish(ctx, events)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package repo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+func TestEventRelay(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		ctx := t.Context()
+		auth := testToken("owner")
+		env.broker.SetError(errors.New("broker unavailable"))
+
+		id := createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+		err := env.repo.UpdateFlow(ctx, id, lib.Flow{Name: "b"}, nil, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.repo.DeleteFlow(ctx, id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.repo.RestoreFlow(ctx, id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = env.repo.relayEvents(ctx)
+		if err == nil {
+			t.Fatal("expected relay to fail while the broker is unavailable")
+		}
+		pending, err := env.db.PendingEvents(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 4 || len(env.broker.Events()) != 0 {
+			t.Fatalf("expected 4 pending and no published events, got %d pending and %d published", len(pending), len(env.broker.Events()))
+		}
+
+		env.broker.SetError(nil)
+		err = env.repo.relayEvents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pending, err = env.db.PendingEvents(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 0 {
+			t.Fatalf("expected empty outbox, got %d events", len(pending))
+		}
+		published := env.broker.Events()
+		expected := []struct {
+			eventType lib.FlowEventType
+			revision  int64
+		}{
+			{lib.FlowCreated, 1},
+			{lib.FlowUpdated, 2},
+			{lib.FlowDeleted, 0},
+			{lib.FlowRestored, 0},
+		}
+		if len(published) != len(expected) {
+			t.Fatalf("expected %d events, got %d", len(expected), len(published))
+		}
+		for i, e := range expected {
+			event := published[i]
+			if event.Type != e.eventType || event.Revision != e.revision || event.FlowId != id || event.UserId != "owner" || event.Version != lib.FlowEventVersion {
+				t.Errorf("unexpected event %d: %+v", i, event)
+			}
+		}
+	})
+}
+
+func TestEventRelayBatches(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		env.cfg.OutboxBatchSize = 2
+		var ids []string
+		for range 5 {
+			ids = append(ids, createFlow(t, env, lib.Flow{Name: "a"}, "owner"))
+		}
+		err := env.repo.relayEvents(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var published []string
+		for _, event := range env.broker.Events() {
+			published = append(published, event.FlowId)
+		}
+		if !slices.Equal(published, ids) {
+			t.Fatalf("expected events of %v in order, got %v", ids, published)
+		}
+	})
+}
+
+func TestEventsDisabled(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		env.repo.events = nil
+		createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+		pending, err := env.db.PendingEvents(t.Context(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 0 {
+			t.Fatalf("expected no events without publisher, got %d", len(pending))
+		}
+	})
+}
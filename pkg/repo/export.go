@@ -0,0 +1,166 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+)
+
+// operatorPageSize is the number of operators requested at once when resolving imported operators.
+const operatorPageSize = 100
+
+// ExportFlow creates a portable bundle of a flow including a snapshot of all referenced operators.
+func (r *Repo) ExportFlow(ctx context.Context, id, userId, auth string) (bundle lib.FlowExport, err error) {
+	flow, err := r.dbRepo.FindFlow(ctx, id, userId, auth)
+	if err != nil {
+		return
+	}
+	bundle = lib.FlowExport{
+		Version:     lib.FlowExportVersion,
+		Name:        flow.Name,
+		Description: flow.Description,
+		Image:       flow.Image,
+		Model:       flow.Model,
+		Tags:        flow.Tags,
+		Operators:   []lib.FlowExportOperator{},
+		DateExport:  time.Now(),
+	}
+	ids := operatorIds(flow.Model)
+	operators, err := r.getOperators(ctx, ids, userId, auth)
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		op := operators[id]
+		bundle.Operators = append(bundle.Operators, lib.FlowExportOperator{
+			Id:             id,
+			Name:           op.Name,
+			Image:          op.Image,
+			Description:    op.Description,
+			DeploymentType: op.DeploymentType,
+			Config:         op.Config,
+			Inputs:         op.Inputs,
+			Outputs:        op.Outputs,
+		})
+	}
+	return
+}
+
+// ImportFlow maps the operators of a bundle to operators of this installation by name and image and stores the flow for the caller.
+// If an operator can not be resolved, no flow is created and the unresolved operators are returned.
+func (r *Repo) ImportFlow(ctx context.Context, bundle lib.FlowExport, userId, auth string) (response lib.FlowImportResponse, err error) {
+	if bundle.Version != lib.FlowExportVersion {
+		return response, lib.NewInputError(fmt.Errorf("unsupported export version %d", bundle.Version))
+	}
+	response.OperatorMapping = map[string]string{}
+	response.UnresolvedOperators = []lib.UnresolvedOperator{}
+	for _, op := range bundle.Operators {
+		id, reason, err := r.resolveOperator(ctx, op, userId, auth)
+		if err != nil {
+			return response, err
+		}
+		if reason != "" {
+			response.UnresolvedOperators = append(response.UnresolvedOperators, lib.UnresolvedOperator{FlowExportOperator: op, Reason: reason})
+			continue
+		}
+		response.OperatorMapping[op.Id] = id
+	}
+
+	cells := make([]lib.Cell, len(bundle.Model.Cells))
+	for i, cell := range bundle.Model.Cells {
+		if cell.Type == NodeElementType && cell.OperatorId != nil {
+			id, ok := response.OperatorMapping[*cell.OperatorId]
+			if !ok {
+				if !slices.ContainsFunc(response.UnresolvedOperators, func(op lib.UnresolvedOperator) bool { return op.Id == *cell.OperatorId }) {
+					response.UnresolvedOperators = append(response.UnresolvedOperators, lib.UnresolvedOperator{
+						FlowExportOperator: lib.FlowExportOperator{Id: *cell.OperatorId},
+						Reason:             "operator missing in export",
+					})
+				}
+				continue
+			}
+			cell.OperatorId = &id
+		}
+		cells[i] = cell
+	}
+	if len(response.UnresolvedOperators) > 0 {
+		return
+	}
+
+	response.Id, err = r.CreateFlow(ctx, lib.Flow{
+		Name:        bundle.Name,
+		Description: bundle.Description,
+		Image:       bundle.Image,
+		Model:       lib.Model{Cells: cells},
+		Tags:        bundle.Tags,
+	}, userId, auth)
+	return
+}
+
+// resolveOperator searches an operator with the same name and image, preferring one with the same id as in the source system.
+// The search of the operator repo is a regular expression, so the name is anchored and all pages of results are checked.
+func (r *Repo) resolveOperator(ctx context.Context, op lib.FlowExportOperator, userId, auth string) (id string, reason string, err error) {
+	if op.Name == "" {
+		return "", "", lib.NewInputError(errors.New("exported operator " + op.Id + " has no name"))
+	}
+	nameMatch := false
+	for offset := 0; ; offset += operatorPageSize {
+		var resp operator_repo.OperatorResponse
+		resp, err = r.operatorRepo.GetOperators(ctx, userId, auth, url.Values{
+			"search": {"^" + regexp.QuoteMeta(op.Name) + "$"},
+			"sort":   {"name:asc"},
+			"limit":  {strconv.Itoa(operatorPageSize)},
+			"offset": {strconv.Itoa(offset)},
+		})
+		if err != nil {
+			return
+		}
+		for _, candidate := range resp.Operators {
+			if candidate.Id == nil || candidate.Name != op.Name {
+				continue
+			}
+			nameMatch = true
+			if candidate.Image != op.Image {
+				continue
+			}
+			if id == "" || candidate.Id.Hex() == op.Id {
+				id = candidate.Id.Hex()
+			}
+		}
+		if len(resp.Operators) < operatorPageSize || int64(offset+len(resp.Operators)) >= resp.Total || id == op.Id {
+			break
+		}
+	}
+	switch {
+	case id != "":
+		return id, "", nil
+	case nameMatch:
+		return "", "no operator with matching image", nil
+	default:
+		return "", "no operator with matching name", nil
+	}
+}
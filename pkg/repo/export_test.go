@@ -0,0 +1,135 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+)
+
+func TestCloneFlow(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		op := testOperator("clone")
+		env := newEnv(op)
+		description := "description"
+		id := createFlow(t, env, lib.Flow{Name: "a", Description: &description, Model: testModel(op), Tags: []string{"x", "y"}}, "owner")
+
+		_, err := env.repo.CloneFlow(t.Context(), id, "other", testToken("other"))
+		if err == nil {
+			t.Fatal("expected flows of other users to not be clonable")
+		}
+		cloneId, err := env.repo.CloneFlow(t.Context(), id, "owner", testToken("owner"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		clone, err := env.repo.GetFlow(t.Context(), cloneId, "owner", testToken("owner"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cloneId == id || clone.Name != "a"+env.cfg.CloneNameSuffix || *clone.Description != description || clone.UserId != "owner" || !slices.Equal(clone.Tags, []string{"x", "y"}) ||
+			len(clone.Model.Cells) != 1 || *clone.Model.Cells[0].OperatorId != op.Id.Hex() || *clone.Model.Cells[0].Name != "clone" {
+			t.Fatalf("unexpected clone %+v", clone)
+		}
+	})
+}
+
+func TestExportImportFlow(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		op := testOperator("export")
+		env := newEnv(op)
+		id := createFlow(t, env, lib.Flow{Name: "a", Model: testModel(op), Tags: []string{"x"}}, "owner")
+		bundle, err := env.repo.ExportFlow(t.Context(), id, "owner", testToken("owner"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bundle.Version != lib.FlowExportVersion || bundle.Name != "a" || !slices.Equal(bundle.Tags, []string{"x"}) || len(bundle.Operators) != 1 || bundle.Operators[0].Id != op.Id.Hex() {
+			t.Fatalf("unexpected export %+v", bundle)
+		}
+
+		// the target installation has an operator with the same name and image but another id
+		target := testOperator("export")
+		targetEnv := newEnv(target)
+		res, err := targetEnv.repo.ImportFlow(t.Context(), bundle, "importer", testToken("importer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Id == "" || len(res.UnresolvedOperators) != 0 || res.OperatorMapping[op.Id.Hex()] != target.Id.Hex() {
+			t.Fatalf("unexpected import response %+v", res)
+		}
+		imported, err := targetEnv.repo.GetFlow(t.Context(), res.Id, "importer", testToken("importer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imported.Name != "a" || imported.UserId != "importer" || !slices.Equal(imported.Tags, []string{"x"}) || *imported.Model.Cells[0].OperatorId != target.Id.Hex() {
+			t.Fatalf("unexpected imported flow %+v", imported)
+		}
+
+		// an operator with the same name but another image is not a match
+		other := testOperator("export")
+		other.Image = "other:latest"
+		otherEnv := newEnv(other)
+		res, err = otherEnv.repo.ImportFlow(t.Context(), bundle, "importer", testToken("importer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Id != "" || len(res.UnresolvedOperators) != 1 || res.UnresolvedOperators[0].Reason != "no operator with matching image" {
+			t.Fatalf("unexpected import response %+v", res)
+		}
+		flows, err := otherEnv.repo.GetFlows(t.Context(), "importer", map[string][]string{}, testToken("importer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(flows.Flows) != 0 {
+			t.Fatalf("expected no flow to be created, got %v", flowNames(flows.Flows))
+		}
+
+		bundle.Version++
+		_, err = targetEnv.repo.ImportFlow(t.Context(), bundle, "importer", testToken("importer"))
+		if err == nil {
+			t.Fatal("expected unsupported export versions to be rejected")
+		}
+	})
+}
+
+func TestImportResolvesOperatorsOnLaterPages(t *testing.T) {
+	op := testOperator("filter.v1")
+	env := newTestEnv(t, op)
+	bundle, err := env.repo.ExportFlow(t.Context(), createFlow(t, env, lib.Flow{Name: "a", Model: testModel(op)}, "owner"), "owner", testToken("owner"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// names which only match an unanchored or unescaped search are ignored, operators with the same name but another image
+	// fill the first pages, an operator with the same name and image comes before the one with the same id
+	operators := []operator_repo.Operator{testOperator("filter.v1-old"), testOperator("filterXv1")}
+	for range 2 * operatorPageSize {
+		other := testOperator("filter.v1")
+		other.Image = "other:latest"
+		operators = append(operators, other)
+	}
+	operators = append(operators, testOperator("filter.v1"), op)
+	res, err := newTestEnv(t, operators...).repo.ImportFlow(t.Context(), bundle, "importer", testToken("importer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Id == "" || res.OperatorMapping[op.Id.Hex()] != op.Id.Hex() {
+		t.Fatalf("unexpected import response %+v", res)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
+)
+
+const (
+	DependencyMongo            = "mongodb"
+	DependencyPermissionsV2    = "permissions_v2"
+	DependencyOperatorRepo     = "operator_repo"
+	DependencyPipelineRegistry = "pipeline_registry"
+)
+
+type dependencyCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Readiness checks all dependencies in parallel, each bounded by the http timeout.
+// The service is not ready if a dependency which is not configured as optional is down.
+func (r *Repo) Readiness(ctx context.Context) lib.ReadinessReport {
+	checks := []dependencyCheck{
+		{DependencyMongo, r.dbRepo.Ping},
+		{DependencyPermissionsV2, r.dbRepo.PingPermissions},
+		{DependencyOperatorRepo, r.operatorRepo.HealthCheck},
+		{DependencyPipelineRegistry, r.pingPipelineRegistry},
+	}
+	report := lib.ReadinessReport{Ready: true, Dependencies: make([]lib.DependencyStatus, len(checks))}
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Go(func() {
+			report.Dependencies[i] = r.checkDependency(ctx, c)
+		})
+	}
+	wg.Wait()
+	for _, status := range report.Dependencies {
+		if !status.Up && !status.Optional {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+func (r *Repo) checkDependency(ctx context.Context, c dependencyCheck) lib.DependencyStatus {
+	status := lib.DependencyStatus{
+		Name:     c.name,
+		Optional: slices.Contains(r.cfg.OptionalDependencies, c.name),
+	}
+	if r.cfg.HttpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.HttpTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := c.check(ctx)
+	status.LatencyMs = time.Since(start).Milliseconds()
+	status.Up = err == nil
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// pingPipelineRegistry calls the health check of the pipeline registry, which is not covered by its client.
+func (r *Repo) pingPipelineRegistry(ctx context.Context) (err error) {
+	defer func() {
+		metrics.CountOutboundCall(metrics.TargetPipelineRegistry, err)
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.PipelineRegistryUrl+"/health-check", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
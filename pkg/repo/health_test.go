@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	operator_api "github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/operator-api"
+	pipelinesClient "github.com/SENERGY-Platform/analytics-pipeline/client"
+	srv_info_hdl "github.com/SENERGY-Platform/go-service-base/srv-info-hdl"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+)
+
+// healthServer responds to health checks with the given status code.
+func healthServer(t *testing.T, code int) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health-check" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// newHealthRepo creates a Repo on a MemoryRepo with the given operator repo and pipeline registry.
+func newHealthRepo(t *testing.T, operatorRepoUrl, pipelineRegistryUrl string, optional ...string) *Repo {
+	cfg, err := config.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.PipelineRegistryUrl = pipelineRegistryUrl
+	cfg.OptionalDependencies = optional
+	perm, err := permV2Client.NewTestClient(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cfg, *srv_info_hdl.New("test", "test"), newMemoryBackend(t, cfg, perm),
+		operator_api.New(operatorRepoUrl, cfg.HttpTimeout, 0), *pipelinesClient.NewClient(pipelineRegistryUrl), nil)
+}
+
+func TestReadiness(t *testing.T) {
+	up := healthServer(t, http.StatusOK)
+	down := healthServer(t, http.StatusServiceUnavailable)
+	tests := []struct {
+		name             string
+		operatorRepo     string
+		pipelineRegistry string
+		optional         []string
+		ready            bool
+		down             []string
+	}{
+		{"all up", up, up, nil, true, nil},
+		{"pipeline registry down", up, down, nil, false, []string{DependencyPipelineRegistry}},
+		{"optional pipeline registry down", up, down, []string{DependencyPipelineRegistry}, true, []string{DependencyPipelineRegistry}},
+		{"operator repo unreachable", "http://127.0.0.1:1", up, nil, false, []string{DependencyOperatorRepo}},
+		{"both down, one optional", down, down, []string{DependencyPipelineRegistry}, false, []string{DependencyOperatorRepo, DependencyPipelineRegistry}},
+	}
+	for _, test := range tests {
+		report := newHealthRepo(t, test.operatorRepo, test.pipelineRegistry, test.optional...).Readiness(t.Context())
+		if report.Ready != test.ready {
+			t.Fatalf("%s: expected ready %v, got %+v", test.name, test.ready, report)
+		}
+		names := map[string]lib.DependencyStatus{}
+		for _, status := range report.Dependencies {
+			names[status.Name] = status
+		}
+		if len(names) != 4 {
+			t.Fatalf("%s: expected 4 dependencies, got %+v", test.name, report.Dependencies)
+		}
+		for name, status := range names {
+			down := slices.Contains(test.down, name)
+			if status.Up == down || (status.Error != "") != down || status.Optional != slices.Contains(test.optional, name) {
+				t.Fatalf("%s: unexpected status %+v", test.name, status)
+			}
+		}
+	}
+}
+
+func TestPingPipelineRegistry(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		error string
+	}{
+		{"up", healthServer(t, http.StatusOK), ""},
+		{"error status", healthServer(t, http.StatusInternalServerError), "status code 500"},
+		{"unreachable", "http://127.0.0.1:1", "connect"},
+	}
+	for _, test := range tests {
+		err := newHealthRepo(t, test.url, test.url).pingPipelineRegistry(t.Context())
+		if test.error == "" && err != nil || test.error != "" && (err == nil || !strings.Contains(err.Error(), test.error)) {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+	}
+}
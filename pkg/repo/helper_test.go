@@ -0,0 +1,234 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/events"
+	operator_api "github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/operator-api"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	pipelinesClient "github.com/SENERGY-Platform/analytics-pipeline/client"
+	srv_info_hdl "github.com/SENERGY-Platform/go-service-base/srv-info-hdl"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMain(m *testing.M) {
+	util.InitStructLogger("error")
+	os.Exit(m.Run())
+}
+
+type testEnv struct {
+	repo             *Repo
+	db               FlowRepository
+	broker           *events.MemoryBroker
+	cfg              *config.Config
+	operatorRequests *atomic.Int64
+}
+
+// backend creates the FlowRepository of a test environment.
+type backend func(t *testing.T, cfg *config.Config, perm permV2Client.Client) FlowRepository
+
+var backends = map[string]backend{
+	"memory": newMemoryBackend,
+	"mongo":  newMongoBackend,
+}
+
+func newMemoryBackend(t *testing.T, cfg *config.Config, perm permV2Client.Client) FlowRepository {
+	db, err := NewMemoryRepo(cfg, perm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newMongoBackend creates a MongoRepo on a new database of the MongoDB given by MONGO_TEST_URL, e.g. localhost:27017.
+// The test is skipped if the variable is not set.
+func newMongoBackend(t *testing.T, cfg *config.Config, perm permV2Client.Client) FlowRepository {
+	url := os.Getenv("MONGO_TEST_URL")
+	if url == "" {
+		t.Skip("MONGO_TEST_URL is not set")
+	}
+	client, err := NewMongoClient(t.Context(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MongoDatabase = "flow_repo_test_" + primitive.NewObjectID().Hex()
+	t.Cleanup(func() {
+		_ = client.Database(cfg.MongoDatabase).Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+	db, err := NewMongoRepo(t.Context(), cfg, client, perm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.EnsureIndexes(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newEnvFunc creates a test environment on the backend of the current subtest.
+type newEnvFunc func(operators ...operator_repo.Operator) testEnv
+
+// forEachBackend runs the test as a subtest for every backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, newEnv newEnvFunc)) {
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			test(t, func(operators ...operator_repo.Operator) testEnv {
+				return newTestEnvWithBackend(t, newBackend, operators...)
+			})
+		})
+	}
+}
+
+// newTestEnv creates a Repo on a MemoryRepo, see newTestEnvWithBackend.
+func newTestEnv(t *testing.T, operators ...operator_repo.Operator) testEnv {
+	t.Helper()
+	return newTestEnvWithBackend(t, newMemoryBackend, operators...)
+}
+
+// newTestEnvWithBackend creates a Repo with mocked permissions, an operator repo serving operators
+// and a pipeline registry which reports all flows as unused.
+func newTestEnvWithBackend(t *testing.T, newBackend backend, operators ...operator_repo.Operator) testEnv {
+	t.Helper()
+	cfg, err := config.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	perm, err := permV2Client.NewTestClient(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newBackend(t, cfg, perm)
+	operatorRequests := new(atomic.Int64)
+	operatorRepo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operatorRequests.Add(1)
+		operatorHandler(operators).ServeHTTP(w, r)
+	}))
+	t.Cleanup(operatorRepo.Close)
+	pipelineRegistry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(pipelineRegistry.Close)
+	broker := events.NewMemoryBroker()
+	return testEnv{
+		repo: New(cfg, *srv_info_hdl.New("test", "test"), db, operator_api.New(operatorRepo.URL, cfg.HttpTimeout, cfg.OperatorCacheTTL),
+			*pipelinesClient.NewClient(pipelineRegistry.URL), broker),
+		db:               db,
+		broker:           broker,
+		cfg:              cfg,
+		operatorRequests: operatorRequests,
+	}
+}
+
+func operatorHandler(operators []operator_repo.Operator) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /operator/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, op := range operators {
+			if op.Id.Hex() == r.PathValue("id") {
+				_ = json.NewEncoder(w).Encode(op)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	// like the operator repo, search is a regular expression. Unlike it, results are limited by default, so callers have to page.
+	mux.HandleFunc("GET /operator", func(w http.ResponseWriter, r *http.Request) {
+		search, err := regexp.Compile(r.URL.Query().Get("search"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp := operator_repo.OperatorResponse{Operators: []operator_repo.Operator{}}
+		for _, op := range operators {
+			if search.MatchString(op.Name) {
+				resp.Operators = append(resp.Operators, op)
+			}
+		}
+		resp.Total = int64(len(resp.Operators))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		resp.Operators = resp.Operators[min(offset, len(resp.Operators)):]
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			limit = 10
+		}
+		resp.Operators = resp.Operators[:min(limit, len(resp.Operators))]
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	return mux
+}
+
+func testOperator(name string) operator_repo.Operator {
+	id := bson.NewObjectID()
+	return operator_repo.Operator{Id: &id, Name: name, Image: name + ":latest", DeploymentType: "cloud"}
+}
+
+// testModel creates a model with one node per operator.
+func testModel(operators ...operator_repo.Operator) lib.Model {
+	model := lib.Model{Cells: []lib.Cell{}}
+	for _, op := range operators {
+		operatorId := op.Id.Hex()
+		model.Cells = append(model.Cells, lib.Cell{Id: "node-" + operatorId, Type: NodeElementType, OperatorId: &operatorId})
+	}
+	return model
+}
+
+// testToken creates an unsigned token, the mocked permissions do not validate signatures.
+func testToken(userId string) string {
+	encode := func(v any) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return "Bearer " + encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encode(map[string]any{
+		"sub":          userId,
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string][]string{"roles": {"user"}},
+	}) + ".c2lnbmF0dXJl"
+}
+
+func createFlow(t *testing.T, env testEnv, flow lib.Flow, userId string) string {
+	t.Helper()
+	id, err := env.repo.CreateFlow(t.Context(), flow, userId, testToken(userId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// isNotFound reports if err is a not found error of any backend, the api maps both to 404.
+func isNotFound(err error) bool {
+	return errors.As(err, new(*lib.NotFoundError)) || errors.Is(err, mongo.ErrNoDocuments)
+}
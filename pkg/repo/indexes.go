@@ -0,0 +1,151 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// flowIndexes, revisionIndexes and outboxIndexes declare all indexes of the service. Every index needs a unique name, which is used to detect drift.
+var flowIndexes = []mongo.IndexModel{
+	// search of All, a collection can only have one text index
+	{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "model.cells.name", Value: "text"},
+		},
+		Options: options.Index().SetName("flow_text").SetWeights(bson.D{
+			{Key: "name", Value: 10},
+			{Key: "description", Value: 5},
+			{Key: "model.cells.name", Value: 1},
+		}),
+	},
+	// operator filter of All and GetOperatorFlowMapping
+	{
+		Keys:    bson.D{{Key: "model.cells.operatorId", Value: 1}},
+		Options: options.Index().SetName("flow_operator"),
+	},
+	// owner part of the permission filter and owner filter of All
+	{
+		Keys:    bson.D{{Key: "userId", Value: 1}},
+		Options: options.Index().SetName("flow_user"),
+	},
+	// sort orders of All
+	{
+		Keys:    bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("flow_name"),
+	},
+	{
+		Keys:    bson.D{{Key: "dateCreated", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("flow_date_created"),
+	},
+	{
+		Keys:    bson.D{{Key: "dateUpdated", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("flow_date_updated"),
+	},
+	// trash and purge
+	{
+		Keys:    bson.D{{Key: "dateDeleted", Value: 1}},
+		Options: options.Index().SetName("flow_date_deleted").SetSparse(true),
+	},
+	{
+		Keys:    bson.D{{Key: "tags", Value: 1}},
+		Options: options.Index().SetName("flow_tags"),
+	},
+}
+
+var revisionIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "flowId", Value: 1}, {Key: "revision", Value: -1}},
+		Options: options.Index().SetName("revision_flow").SetUnique(true),
+	},
+}
+
+// relay order of PendingEvents
+var outboxIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("outbox_time"),
+	},
+}
+
+// EnsureIndexes creates all missing indexes. Indexes with changed keys and indexes that are not declared are only logged,
+// they have to be dropped manually.
+func (r *MongoRepo) EnsureIndexes(ctx context.Context) error {
+	return errors.Join(
+		ensureIndexes(ctx, r.flows, flowIndexes),
+		ensureIndexes(ctx, r.revisions, revisionIndexes),
+		ensureIndexes(ctx, r.outbox, outboxIndexes),
+	)
+}
+
+func ensureIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongo.IndexModel) error {
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	existing := map[string]*mongo.IndexSpecification{}
+	for _, spec := range specs {
+		existing[spec.Name] = spec
+	}
+	var errs []error
+	declared := map[string]bool{"_id_": true}
+	for _, index := range indexes {
+		name := *index.Options.Name
+		declared[name] = true
+		spec, ok := existing[name]
+		if !ok {
+			util.Logger.Info("creating index", "collection", collection.Name(), "index", name)
+			_, err = collection.Indexes().CreateOne(ctx, index)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		keys, err := bson.Marshal(index.Keys)
+		if err != nil {
+			return err
+		}
+		if !isTextIndex(index) && !bytes.Equal(keys, spec.KeysDocument) {
+			util.Logger.Warn("index drift: keys differ from declaration", "collection", collection.Name(), "index", name, "existing", spec.KeysDocument.String())
+		}
+	}
+	for name := range existing {
+		if !declared[name] {
+			util.Logger.Warn("index drift: undeclared index", "collection", collection.Name(), "index", name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isTextIndex reports if the index contains text keys. Mongo stores text indexes with internal keys, so they can only be compared by name.
+func isTextIndex(index mongo.IndexModel) bool {
+	for _, key := range index.Keys.(bson.D) {
+		if key.Value == "text" {
+			return true
+		}
+	}
+	return false
+}
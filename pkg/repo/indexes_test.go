@@ -0,0 +1,109 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIndexDeclarations(t *testing.T) {
+	declared := map[string]mongo.IndexModel{}
+	for _, indexes := range [][]mongo.IndexModel{flowIndexes, revisionIndexes, outboxIndexes} {
+		for _, index := range indexes {
+			name := *index.Options.Name
+			if _, ok := declared[name]; ok {
+				t.Fatalf("index name %s is not unique", name)
+			}
+			declared[name] = index
+		}
+	}
+
+	tests := []struct {
+		name   string
+		keys   bson.D
+		unique bool
+		sparse bool
+	}{
+		{"flow_text", bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}, {Key: "model.cells.name", Value: "text"}}, false, false},
+		{"flow_operator", bson.D{{Key: "model.cells.operatorId", Value: 1}}, false, false},
+		{"flow_user", bson.D{{Key: "userId", Value: 1}}, false, false},
+		{"flow_name", bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, false, false},
+		{"flow_tags", bson.D{{Key: "tags", Value: 1}}, false, false},
+		{"flow_date_deleted", bson.D{{Key: "dateDeleted", Value: 1}}, false, true},
+		{"revision_flow", bson.D{{Key: "flowId", Value: 1}, {Key: "revision", Value: -1}}, true, false},
+		{"outbox_time", bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}}, false, false},
+	}
+	for _, test := range tests {
+		index, ok := declared[test.name]
+		if !ok {
+			t.Fatalf("index %s is not declared", test.name)
+		}
+		if !reflect.DeepEqual(index.Keys, test.keys) {
+			t.Fatalf("index %s: unexpected keys %v", test.name, index.Keys)
+		}
+		unique := index.Options.Unique != nil && *index.Options.Unique
+		sparse := index.Options.Sparse != nil && *index.Options.Sparse
+		if unique != test.unique || sparse != test.sparse {
+			t.Fatalf("index %s: unique %v, sparse %v", test.name, unique, sparse)
+		}
+		if isTextIndex(index) != (test.name == "flow_text") {
+			t.Fatalf("index %s: wrong text index detection", test.name)
+		}
+	}
+}
+
+func TestEnsureIndexes(t *testing.T) {
+	cfg, err := config.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	perm, err := permV2Client.NewTestClient(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the backend already ensured the indexes, a second run must not fail on the existing ones
+	r := newMongoBackend(t, cfg, perm).(*MongoRepo)
+	err = r.EnsureIndexes(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for collection, indexes := range map[*mongo.Collection][]mongo.IndexModel{r.flows: flowIndexes, r.revisions: revisionIndexes, r.outbox: outboxIndexes} {
+		specs, err := collection.Indexes().ListSpecifications(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		existing := map[string]*mongo.IndexSpecification{}
+		for _, spec := range specs {
+			existing[spec.Name] = spec
+		}
+		for _, index := range indexes {
+			spec, ok := existing[*index.Options.Name]
+			if !ok {
+				t.Fatalf("index %s was not created", *index.Options.Name)
+			}
+			if index.Options.Unique != nil && (spec.Unique == nil || !*spec.Unique) {
+				t.Fatalf("index %s is not unique", spec.Name)
+			}
+		}
+	}
+}
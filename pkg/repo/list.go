@@ -0,0 +1,171 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// listOptions holds the parsed query arguments of a flow listing, shared by all FlowRepository implementations.
+type listOptions struct {
+	sortField  string
+	sortOrder  int64
+	limit      int64
+	skip       int64
+	countTotal bool
+	summary    bool
+	projection bson.M
+	search     string
+	filters    []listFilter
+	cursorMode bool
+	cursor     *flowCursor
+}
+
+type listFilter struct {
+	key       string
+	values    []string
+	dateRange bson.M
+}
+
+func parseListOptions(args map[string][]string) (opts listOptions, err error) {
+	opts.countTotal = true
+	for arg, value := range args {
+		if len(value) == 0 {
+			continue
+		}
+
+		switch arg {
+		case "sort":
+			sortFields := []string{"name", "dateCreated", "dateUpdated", "score"}
+			ord := strings.SplitN(value[0], ":", 2)
+			if len(ord) == 2 {
+				field, dir := ord[0], ord[1]
+				if slices.Contains(sortFields, field) {
+					order := int64(1)
+					if dir == "desc" {
+						order = -1
+					}
+					opts.sortField, opts.sortOrder = field, order
+				}
+			}
+		case "limit":
+			opts.limit, err = strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return opts, lib.NewInputError(errors.New("invalid limit " + value[0]))
+			}
+		case "offset":
+			opts.skip, err = strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return opts, lib.NewInputError(errors.New("invalid offset " + value[0]))
+			}
+		case "total":
+			opts.countTotal = value[0] != "false"
+		case "summary":
+			opts.summary = value[0] == "true"
+		case "fields":
+			opts.projection, err = parseProjection(value[0])
+			if err != nil {
+				return
+			}
+		case "search":
+			opts.search = value[0]
+		case "filter":
+			opts.filters, err = parseListFilters(value)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	// the relevance score is only available for text searches, results are ranked by it unless another sort order is requested
+	if opts.search != "" && opts.sortField == "" {
+		opts.sortField, opts.sortOrder = "score", -1
+	}
+	if opts.search == "" && opts.sortField == "score" {
+		opts.sortField, opts.sortOrder = "", 0
+	}
+
+	cursor, cursorMode := args["cursor"]
+	if cursorMode {
+		opts.cursorMode = true
+		if opts.sortField == "" {
+			opts.sortField, opts.sortOrder = "_id", 1
+		}
+		opts.skip = 0
+		if len(cursor) > 0 && cursor[0] != "" {
+			var c flowCursor
+			c, err = decodeFlowCursor(cursor[0], opts.sortField, opts.sortOrder)
+			if err != nil {
+				return
+			}
+			opts.cursor = &c
+		}
+	}
+	return
+}
+
+func parseListFilters(raw []string) (filters []listFilter, err error) {
+	for _, r := range raw {
+		for _, f := range strings.Split(r, "|") {
+			if f == "" {
+				continue
+			}
+
+			parts := strings.SplitN(f, ":", 2)
+			if len(parts) != 2 {
+				return nil, lib.NewInputError(errors.New("invalid filter " + f))
+			}
+
+			filter := listFilter{key: parts[0], values: strings.Split(parts[1], ",")}
+			switch filter.key {
+			case "operator", "deploymentType", "tag", "owner":
+			case "dateCreated", "dateUpdated":
+				filter.dateRange, err = parseDateRange(filter.values)
+				if err != nil {
+					return
+				}
+			default:
+				return nil, lib.NewInputError(errors.New("unknown filter " + filter.key))
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return
+}
+
+// pageLimit is the number of flows to query. In cursor mode one more flow is queried to detect if another page exists.
+func (o listOptions) pageLimit() int64 {
+	if o.cursorMode && o.limit > 0 {
+		return o.limit + 1
+	}
+	return o.limit
+}
+
+// finishPage trims a page queried with pageLimit and sets the cursor for the next page.
+func (o listOptions) finishPage(response *lib.FlowsResponse) (err error) {
+	if o.cursorMode && o.limit > 0 && int64(len(response.Flows)) > o.limit {
+		response.Flows = response.Flows[:o.limit]
+		response.NextCursor, err = newFlowCursor(o.sortField, o.sortOrder, response.Flows[o.limit-1]).encode()
+	}
+	return
+}
@@ -0,0 +1,672 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepo is a FlowRepository which keeps all flows in memory, for tests and local development.
+// It behaves like MongoRepo, permissions are still managed by permissions-v2, which can be mocked.
+type MemoryRepo struct {
+	flowPermissions
+	mux            sync.RWMutex
+	flows          map[string]lib.Flow
+	revisions      map[string][]lib.FlowRevision
+	outbox         []lib.FlowEvent
+	revisionLimit  int
+	revisionMaxAge time.Duration
+}
+
+func NewMemoryRepo(cfg *config.Config, perm permV2Client.Client) (*MemoryRepo, error) {
+	permissions, err := newFlowPermissions(cfg, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryRepo{
+		flowPermissions: permissions,
+		flows:           map[string]lib.Flow{},
+		revisions:       map[string][]lib.FlowRevision{},
+		revisionLimit:   cfg.RevisionLimit,
+		revisionMaxAge:  cfg.RevisionMaxAge,
+	}, nil
+}
+
+func (r *MemoryRepo) InsertFlow(ctx context.Context, flow lib.Flow) (id string, revision int64, err error) {
+	flow.Summary = nil
+	flow.Score = nil
+	flow.DateDeleted = nil
+	flow.Tags = normalizeTags(flow.Tags)
+	flow.DateCreated = time.Now()
+	flow.DateUpdated = time.Now()
+	objID := primitive.NewObjectID()
+	flow.Id = &objID
+	id = objID.Hex()
+	permissions := permV2Client.ResourcePermissions{
+		GroupPermissions: map[string]permV2Client.PermissionsMap{},
+		UserPermissions:  map[string]permV2Client.PermissionsMap{},
+		RolePermissions:  map[string]permV2Model.PermissionsMap{},
+	}
+	SetDefaultPermissions(flow, permissions)
+	r.mux.Lock()
+	r.flows[id] = cloneFlow(flow)
+	r.mux.Unlock()
+	_, err = r.setPermission(ctx, id, permissions)
+	if err != nil {
+		err = lib.NewExternalResourceError(err)
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	revision = r.saveRevision(id, flow, flow.UserId, nil)
+	return
+}
+
+func (r *MemoryRepo) UpdateFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64, userId string, auth string) (revision int64, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Write)
+	if err != nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	current, ok := r.flows[id]
+	if !ok || current.DateDeleted != nil {
+		return 0, lib.NewNotFoundError(errors.New("could not find flow " + id))
+	}
+	if expectedVersion != nil && *expectedVersion != current.Version {
+		return 0, lib.NewPreconditionFailedError(fmt.Errorf("flow %s has version %d, expected %d", id, current.Version, *expectedVersion))
+	}
+	flow.Id = current.Id
+	flow.UserId = current.UserId
+	flow.DateCreated = current.DateCreated
+	flow.DateUpdated = time.Now()
+	flow.DateDeleted = current.DateDeleted
+	flow.Version = current.Version + 1
+	flow.Tags = normalizeTags(flow.Tags)
+	flow.Summary = nil
+	flow.Score = nil
+	r.flows[id] = cloneFlow(flow)
+	return r.saveRevision(id, flow, userId, &current), nil
+}
+
+func (r *MemoryRepo) DeleteFlow(ctx context.Context, id string, _ string, _ bool, auth string) (err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	flow, ok := r.flows[id]
+	if !ok || flow.DateDeleted != nil {
+		return lib.NewNotFoundError(errors.New("could not find flow " + id))
+	}
+	now := time.Now()
+	flow.DateDeleted = &now
+	r.flows[id] = cloneFlow(flow)
+	return
+}
+
+func (r *MemoryRepo) RestoreFlow(ctx context.Context, id string, _ string, auth string) (err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	flow, ok := r.flows[id]
+	if !ok || flow.DateDeleted == nil {
+		return lib.NewNotFoundError(errors.New("could not find deleted flow " + id))
+	}
+	flow.DateDeleted = nil
+	r.flows[id] = cloneFlow(flow)
+	return
+}
+
+func (r *MemoryRepo) PurgeFlows(ctx context.Context, deletedBefore time.Time) (count int, err error) {
+	r.mux.RLock()
+	var ids []string
+	for id, flow := range r.flows {
+		if flow.DateDeleted != nil && flow.DateDeleted.Before(deletedBefore) {
+			ids = append(ids, id)
+		}
+	}
+	r.mux.RUnlock()
+	for _, id := range ids {
+		err = r.removeResource(ctx, id)
+		if err != nil {
+			return count, lib.NewExternalResourceError(err)
+		}
+		r.mux.Lock()
+		delete(r.revisions, id)
+		delete(r.flows, id)
+		r.mux.Unlock()
+		count++
+	}
+	return
+}
+
+func (r *MemoryRepo) All(ctx context.Context, userId string, admin bool, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
+	return r.list(ctx, userId, admin, args, auth, permV2Client.Read, false)
+}
+
+func (r *MemoryRepo) AllDeleted(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
+	return r.list(ctx, userId, false, args, auth, permV2Client.Administrate, true)
+}
+
+func (r *MemoryRepo) list(ctx context.Context, userId string, admin bool, args map[string][]string, auth string, permission permV2Client.Permission, deleted bool) (response lib.FlowsResponse, err error) {
+	opts, err := parseListOptions(args)
+	if err != nil {
+		return
+	}
+	accessible, err := r.accessible(ctx, userId, admin, auth, permission)
+	if err != nil {
+		return
+	}
+	terms := searchTerms(opts.search)
+
+	flows := r.find(func(flow lib.Flow) bool {
+		return (flow.DateDeleted != nil) == deleted && accessible(flow)
+	})
+	matches := make([]lib.Flow, 0, len(flows))
+	for _, flow := range flows {
+		if len(terms) > 0 {
+			score := searchScore(flow, terms)
+			if score == 0 {
+				continue
+			}
+			flow.Score = &score
+		}
+		if !slices.ContainsFunc(opts.filters, func(filter listFilter) bool { return !matchesFilter(flow, filter) }) {
+			matches = append(matches, flow)
+		}
+	}
+	if opts.countTotal {
+		response.Total = int64(len(matches))
+	} else {
+		response.Total = -1
+	}
+
+	slices.SortFunc(matches, func(a, b lib.Flow) int {
+		c := 0
+		if opts.sortField != "" && opts.sortField != "_id" {
+			c = compareSortValues(sortValue(a, opts.sortField), sortValue(b, opts.sortField))
+		}
+		if c == 0 {
+			c = strings.Compare(a.Id.Hex(), b.Id.Hex())
+		}
+		if opts.sortOrder < 0 {
+			return -c
+		}
+		return c
+	})
+	if opts.cursor != nil {
+		matches = slices.DeleteFunc(matches, func(flow lib.Flow) bool { return !opts.cursor.after(flow) })
+	}
+	matches = matches[min(opts.skip, int64(len(matches))):]
+	if limit := opts.pageLimit(); limit > 0 && int64(len(matches)) > limit {
+		matches = matches[:limit]
+	}
+
+	response.Flows = make([]lib.Flow, 0, len(matches))
+	for _, flow := range matches {
+		if opts.summary {
+			flow.Summary = modelSummary(flow.Model)
+		}
+		if opts.projection != nil {
+			flow = projectFlow(flow, opts)
+		} else if opts.summary {
+			flow.Model = lib.Model{}
+		}
+		response.Flows = append(response.Flows, flow)
+	}
+	err = opts.finishPage(&response)
+	return
+}
+
+// accessible returns a check for flows the user has the given permission for or owns.
+func (r *MemoryRepo) accessible(ctx context.Context, userId string, admin bool, auth string, permission permV2Client.Permission) (func(lib.Flow) bool, error) {
+	if admin {
+		return func(lib.Flow) bool { return true }, nil
+	}
+	ids, err := r.accessibleIds(ctx, auth, permission)
+	if err != nil {
+		return nil, err
+	}
+	return func(flow lib.Flow) bool {
+		return flow.UserId == userId || slices.Contains(ids, flow.Id.Hex())
+	}, nil
+}
+
+// find returns copies of all flows matching the predicate.
+func (r *MemoryRepo) find(predicate func(lib.Flow) bool) (flows []lib.Flow) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	for _, flow := range r.flows {
+		if predicate(flow) {
+			flows = append(flows, cloneFlow(flow))
+		}
+	}
+	return
+}
+
+func (r *MemoryRepo) AllTags(ctx context.Context, userId string, auth string) (tags []lib.TagCount, err error) {
+	accessible, err := r.accessible(ctx, userId, false, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	counts := map[string]int64{}
+	for _, flow := range r.find(func(flow lib.Flow) bool { return flow.DateDeleted == nil && accessible(flow) }) {
+		for _, tag := range flow.Tags {
+			counts[tag]++
+		}
+	}
+	tags = make([]lib.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, lib.TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b lib.TagCount) int { return strings.Compare(a.Tag, b.Tag) })
+	return
+}
+
+func (r *MemoryRepo) FindFlow(ctx context.Context, id, _, auth string) (flow lib.Flow, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	flow, ok := r.flows[id]
+	if !ok || flow.DateDeleted != nil {
+		return lib.Flow{}, lib.NewNotFoundError(errors.New("could not find flow " + id))
+	}
+	return cloneFlow(flow), nil
+}
+
+func (r *MemoryRepo) CountFlows(_ context.Context) (count int64, err error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	for _, flow := range r.flows {
+		if flow.DateDeleted == nil {
+			count++
+		}
+	}
+	return
+}
+
+func (r *MemoryRepo) Ping(_ context.Context) error {
+	return nil
+}
+
+func (r *MemoryRepo) GetOperatorFlowMapping(_ context.Context) ([]lib.OperatorFlowCount, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	usage := map[string]map[primitive.ObjectID]int32{}
+	for _, flow := range r.flows {
+		if flow.DateDeleted != nil {
+			continue
+		}
+		for _, cell := range flow.Model.Cells {
+			if cell.Type != NodeElementType {
+				continue
+			}
+			operatorId := ""
+			if cell.OperatorId != nil {
+				operatorId = *cell.OperatorId
+			}
+			if usage[operatorId] == nil {
+				usage[operatorId] = map[primitive.ObjectID]int32{}
+			}
+			usage[operatorId][*flow.Id]++
+		}
+	}
+	results := make([]lib.OperatorFlowCount, 0, len(usage))
+	for operatorId, flows := range usage {
+		result := lib.OperatorFlowCount{OperatorID: operatorId}
+		for flowId, count := range flows {
+			result.Flows = append(result.Flows, lib.FlowCount{FlowID: &flowId, Count: count})
+		}
+		slices.SortFunc(result.Flows, func(a, b lib.FlowCount) int { return strings.Compare(a.FlowID.Hex(), b.FlowID.Hex()) })
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b lib.OperatorFlowCount) int { return strings.Compare(a.OperatorID, b.OperatorID) })
+	return results, nil
+}
+
+// WithTransaction runs fn directly, changes of a MemoryRepo can not be rolled back.
+func (r *MemoryRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// saveRevision mirrors MongoRepo.saveRevision, the caller has to hold the write lock.
+func (r *MemoryRepo) saveRevision(id string, flow lib.Flow, author string, previous *lib.Flow) int64 {
+	revisions := r.revisions[id]
+	latest := int64(0)
+	if len(revisions) > 0 {
+		latest = revisions[len(revisions)-1].Revision
+	}
+	if latest == 0 && previous != nil {
+		latest++
+		revisions = append(revisions, newRevision(id, latest, *previous, previous.UserId, previous.DateUpdated))
+	}
+	latest++
+	revisions = append(revisions, newRevision(id, latest, flow, author, flow.DateUpdated))
+	revisions = slices.DeleteFunc(revisions, func(revision lib.FlowRevision) bool {
+		if revision.Revision == latest {
+			return false
+		}
+		return (r.revisionLimit > 0 && revision.Revision <= latest-int64(r.revisionLimit)) ||
+			(r.revisionMaxAge > 0 && revision.Timestamp.Before(time.Now().Add(-r.revisionMaxAge)))
+	})
+	r.revisions[id] = revisions
+	return latest
+}
+
+func (r *MemoryRepo) InsertEvent(_ context.Context, event lib.FlowEvent) (err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.outbox = append(r.outbox, event)
+	return
+}
+
+func (r *MemoryRepo) PendingEvents(_ context.Context, limit int64) (events []lib.FlowEvent, err error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	events = slices.Clone(r.outbox)
+	if limit > 0 && int64(len(events)) > limit {
+		events = events[:limit]
+	}
+	return
+}
+
+func (r *MemoryRepo) RemoveEvents(_ context.Context, ids []string) (err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.outbox = slices.DeleteFunc(r.outbox, func(event lib.FlowEvent) bool {
+		return slices.Contains(ids, event.Id)
+	})
+	return
+}
+
+func newRevision(id string, revision int64, flow lib.Flow, author string, timestamp time.Time) lib.FlowRevision {
+	model := cloneFlow(flow).Model
+	return lib.FlowRevision{
+		FlowId:      id,
+		Revision:    revision,
+		Name:        flow.Name,
+		Description: flow.Description,
+		Model:       &model,
+		Author:      author,
+		Timestamp:   timestamp,
+	}
+}
+
+func (r *MemoryRepo) AllRevisions(ctx context.Context, id, _ string, args map[string][]string, auth string) (response lib.FlowRevisionsResponse, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	var limit, skip int64
+	if value, ok := args["limit"]; ok && len(value) > 0 {
+		limit, err = strconv.ParseInt(value[0], 10, 64)
+		if err != nil {
+			return response, lib.NewInputError(errors.New("invalid limit " + value[0]))
+		}
+	}
+	if value, ok := args["offset"]; ok && len(value) > 0 {
+		skip, err = strconv.ParseInt(value[0], 10, 64)
+		if err != nil {
+			return response, lib.NewInputError(errors.New("invalid offset " + value[0]))
+		}
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	revisions := r.revisions[id]
+	response.Total = int64(len(revisions))
+	response.Revisions = make([]lib.FlowRevision, 0)
+	for i := len(revisions) - 1 - int(max(skip, 0)); i >= 0; i-- {
+		if limit > 0 && int64(len(response.Revisions)) >= limit {
+			break
+		}
+		revision := revisions[i]
+		revision.Model = nil
+		response.Revisions = append(response.Revisions, revision)
+	}
+	return
+}
+
+func (r *MemoryRepo) FindRevision(ctx context.Context, id string, revision int64, _, auth string) (flowRevision lib.FlowRevision, err error) {
+	err = r.checkPermission(ctx, id, auth, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	for _, flowRevision = range r.revisions[id] {
+		if flowRevision.Revision == revision {
+			return
+		}
+	}
+	return lib.FlowRevision{}, lib.NewNotFoundError(fmt.Errorf("could not find revision %d of flow %s", revision, id))
+}
+
+// cloneFlow deep copies a flow the same way it would be stored in mongo.
+func cloneFlow(flow lib.Flow) (clone lib.Flow) {
+	b, err := bson.Marshal(flow)
+	if err != nil {
+		panic(err)
+	}
+	err = bson.Unmarshal(b, &clone)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+func matchesFilter(flow lib.Flow, filter listFilter) bool {
+	switch filter.key {
+	case "operator":
+		return slices.ContainsFunc(flow.Model.Cells, func(cell lib.Cell) bool {
+			return cell.Type == NodeElementType && cell.OperatorId != nil && slices.Contains(filter.values, *cell.OperatorId)
+		})
+	case "deploymentType":
+		return slices.ContainsFunc(flow.Model.Cells, func(cell lib.Cell) bool {
+			return cell.Type == NodeElementType && cell.DeploymentType != nil && slices.Contains(filter.values, *cell.DeploymentType)
+		})
+	case "dateCreated", "dateUpdated":
+		date := flow.DateCreated
+		if filter.key == "dateUpdated" {
+			date = flow.DateUpdated
+		}
+		if after, ok := filter.dateRange["$gt"].(time.Time); ok && !date.After(after) {
+			return false
+		}
+		if before, ok := filter.dateRange["$lt"].(time.Time); ok && !date.Before(before) {
+			return false
+		}
+		return true
+	case "tag":
+		return slices.ContainsFunc(flow.Tags, func(tag string) bool { return slices.Contains(filter.values, tag) })
+	default:
+		return slices.Contains(filter.values, flow.UserId)
+	}
+}
+
+func searchTerms(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// searchScore approximates the weights of the text index: name 10, description 5 and node names 1 per matching word.
+func searchScore(flow lib.Flow, terms []string) (score float64) {
+	count := func(text string) (n float64) {
+		for _, word := range searchTerms(text) {
+			if slices.Contains(terms, word) {
+				n++
+			}
+		}
+		return
+	}
+	score += 10 * count(flow.Name)
+	if flow.Description != nil {
+		score += 5 * count(*flow.Description)
+	}
+	for _, cell := range flow.Model.Cells {
+		if cell.Name != nil {
+			score += count(*cell.Name)
+		}
+	}
+	return
+}
+
+func sortValue(flow lib.Flow, field string) interface{} {
+	switch field {
+	case "name":
+		return flow.Name
+	case "dateCreated":
+		return flow.DateCreated
+	case "dateUpdated":
+		return flow.DateUpdated
+	case "score":
+		if flow.Score != nil {
+			return *flow.Score
+		}
+	}
+	return nil
+}
+
+// compareSortValues compares sort values of flows and cursors. Missing values sort first, like in mongo.
+func compareSortValues(a, b interface{}) int {
+	if dt, ok := a.(primitive.DateTime); ok {
+		a = dt.Time()
+	}
+	if dt, ok := b.(primitive.DateTime); ok {
+		b = dt.Time()
+	}
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	}
+	if b == nil {
+		return 1
+	}
+	return 0
+}
+
+// after reports if the flow is behind the cursor position, see filter.
+func (c flowCursor) after(flow lib.Flow) bool {
+	result := 0
+	if c.Field != "_id" {
+		result = compareSortValues(sortValue(flow, c.Field), c.Value)
+	}
+	if result == 0 {
+		result = strings.Compare(flow.Id.Hex(), c.Id.Hex())
+	}
+	if c.Order < 0 {
+		result = -result
+	}
+	return result > 0
+}
+
+func modelSummary(model lib.Model) *lib.FlowSummary {
+	summary := lib.FlowSummary{}
+	for _, cell := range model.Cells {
+		if cell.Source != nil || cell.Target != nil {
+			summary.Links++
+		} else {
+			summary.Nodes++
+		}
+	}
+	return &summary
+}
+
+// projectFlow keeps the id and the projected fields of a flow, see parseProjection.
+func projectFlow(flow lib.Flow, opts listOptions) lib.Flow {
+	keep := func(field string) bool {
+		_, ok := opts.projection[field]
+		return ok || field == opts.sortField
+	}
+	projected := lib.Flow{Id: flow.Id}
+	if keep("name") {
+		projected.Name = flow.Name
+	}
+	if keep("description") {
+		projected.Description = flow.Description
+	}
+	if keep("model") {
+		projected.Model = flow.Model
+	}
+	if keep("image") {
+		projected.Image = flow.Image
+	}
+	if keep("userId") {
+		projected.UserId = flow.UserId
+	}
+	if keep("dateCreated") {
+		projected.DateCreated = flow.DateCreated
+	}
+	if keep("dateUpdated") {
+		projected.DateUpdated = flow.DateUpdated
+	}
+	if keep("dateDeleted") {
+		projected.DateDeleted = flow.DateDeleted
+	}
+	if keep("version") {
+		projected.Version = flow.Version
+	}
+	if keep("tags") {
+		projected.Tags = flow.Tags
+	}
+	if keep("score") {
+		projected.Score = flow.Score
+	}
+	if opts.summary {
+		projected.Summary = flow.Summary
+	}
+	return projected
+}
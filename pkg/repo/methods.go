@@ -17,8 +17,14 @@
 package repo
 
 import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
 	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func SetDefaultPermissions(instance lib.Flow, permissions permV2Client.ResourcePermissions) {
@@ -29,3 +35,46 @@ func SetDefaultPermissions(instance lib.Flow, permissions permV2Client.ResourceP
 		Administrate: true,
 	}
 }
+
+// normalizeTags trims tags and removes empty and duplicate ones.
+func normalizeTags(tags []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// parseDateRange parses filter values like after=2025-01-01 or before=2025-01-01T12:00:00Z into a mongo range query.
+func parseDateRange(values []string) (dateRange bson.M, err error) {
+	dateRange = bson.M{}
+	for _, value := range values {
+		op, date, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, lib.NewInputError(errors.New("invalid date range " + value))
+		}
+		var t time.Time
+		t, err = time.Parse(time.RFC3339, date)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, date)
+		}
+		if err != nil {
+			return nil, lib.NewInputError(errors.New("invalid date " + date))
+		}
+		switch op {
+		case "after":
+			dateRange["$gt"] = t
+		case "before":
+			dateRange["$lt"] = t
+		default:
+			return nil, lib.NewInputError(errors.New("invalid date range " + value))
+		}
+	}
+	return
+}
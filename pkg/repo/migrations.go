@@ -0,0 +1,188 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type migration struct {
+	version     int
+	description string
+	// run applies the migration and returns the number of affected documents. With dryRun set, it only counts them.
+	run func(ctx context.Context, r *MongoRepo, dryRun bool) (affected int64, err error)
+}
+
+type migrationRecord struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	Affected    int64     `bson:"affected"`
+	DateApplied time.Time `bson:"dateApplied"`
+}
+
+// migrations lists all schema migrations in ascending order. Applied migrations must never be changed, add a new one instead.
+// Migrations must be idempotent, concurrently starting instances may run the same migration.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "rename flow field userid to userId",
+		run: func(ctx context.Context, r *MongoRepo, dryRun bool) (int64, error) {
+			return updateMany(ctx, r.flows, bson.M{
+				"userid": bson.M{"$exists": true},
+				"userId": bson.M{"$exists": false},
+			}, bson.M{"$rename": bson.M{"userid": "userId"}}, dryRun)
+		},
+	},
+	{
+		version:     2,
+		description: "rename lowercase model cell fields to camel case",
+		run: func(ctx context.Context, r *MongoRepo, dryRun bool) (affected int64, err error) {
+			for _, collection := range []*mongo.Collection{r.flows, r.revisions} {
+				var n int64
+				n, err = updateMany(ctx, collection, legacyCellFilter(), bson.A{renameCellFields()}, dryRun)
+				if err != nil {
+					return
+				}
+				affected += n
+			}
+			if dryRun {
+				return
+			}
+			// the operator index has to be rebuilt on the renamed field by EnsureIndexes,
+			// neither the index nor the collection exist on a fresh database
+			_, err = r.flows.Indexes().DropOne(ctx, "flow_operator")
+			var cmdErr mongo.CommandError
+			if errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+				err = nil
+			}
+			return
+		},
+	},
+}
+
+var legacyCellFields = map[string]string{
+	"inports":        "inPorts",
+	"outports":       "outPorts",
+	"operatorid":     "operatorId",
+	"deploymenttype": "deploymentType",
+}
+
+func legacyCellFilter() bson.M {
+	fields := bson.A{}
+	for legacy := range legacyCellFields {
+		fields = append(fields, bson.M{legacy: bson.M{"$exists": true}})
+	}
+	return bson.M{"model.cells": bson.M{"$elemMatch": bson.M{"$or": fields}}}
+}
+
+// renameCellFields returns an update stage renaming the keys of every model cell.
+func renameCellFields() bson.M {
+	branches := bson.A{}
+	for legacy, name := range legacyCellFields {
+		branches = append(branches, bson.M{"case": bson.M{"$eq": bson.A{"$$field.k", legacy}}, "then": name})
+	}
+	return bson.M{"$set": bson.M{"model.cells": bson.M{"$map": bson.M{
+		"input": "$model.cells",
+		"as":    "cell",
+		"in": bson.M{"$arrayToObject": bson.M{"$map": bson.M{
+			"input": bson.M{"$objectToArray": "$$cell"},
+			"as":    "field",
+			"in": bson.M{
+				"k": bson.M{"$switch": bson.M{"branches": branches, "default": "$$field.k"}},
+				"v": "$$field.v",
+			},
+		}}},
+	}}}}
+}
+
+func updateMany(ctx context.Context, collection *mongo.Collection, filter bson.M, update interface{}, dryRun bool) (int64, error) {
+	if dryRun {
+		return collection.CountDocuments(ctx, filter)
+	}
+	res, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// Migrate applies all pending migrations in order and records them in the migrations collection.
+// With dryRun set, pending migrations and the number of documents they would change are only logged.
+func (r *MongoRepo) Migrate(ctx context.Context, dryRun bool) (err error) {
+	cursor, err := r.migrations.Find(ctx, bson.M{})
+	if err != nil {
+		return
+	}
+	var records []migrationRecord
+	err = cursor.All(ctx, &records)
+	if err != nil {
+		return
+	}
+	applied := map[int]bool{}
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if dryRun {
+			var affected int64
+			affected, err = m.run(ctx, r, true)
+			if err != nil {
+				return
+			}
+			util.Logger.Info("pending migration", "version", m.version, "description", m.description, "affected", affected)
+			continue
+		}
+		err = r.applyMigration(ctx, m)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// applyMigration runs the migration and records it afterwards, so a failed migration is retried on the next start.
+// If another instance applied it concurrently, its record is kept.
+func (r *MongoRepo) applyMigration(ctx context.Context, m migration) (err error) {
+	util.Logger.Info("applying migration", "version", m.version, "description", m.description)
+	affected, err := m.run(ctx, r, false)
+	if err != nil {
+		return errors.New("migration " + m.description + " failed: " + err.Error())
+	}
+	_, err = r.migrations.InsertOne(ctx, migrationRecord{
+		Version:     m.version,
+		Description: m.description,
+		Affected:    affected,
+		DateApplied: time.Now().UTC(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	util.Logger.Info("applied migration", "version", m.version, "affected", affected)
+	return
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLegacyCellFilter(t *testing.T) {
+	or := legacyCellFilter()["model.cells"].(bson.M)["$elemMatch"].(bson.M)["$or"].(bson.A)
+	var fields []string
+	for _, condition := range or {
+		for field := range condition.(bson.M) {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	if !slices.Equal(fields, []string{"deploymenttype", "inports", "operatorid", "outports"}) {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
+
+func TestRenameCellFields(t *testing.T) {
+	mapped := renameCellFields()["$set"].(bson.M)["model.cells"].(bson.M)["$map"].(bson.M)
+	if mapped["input"] != "$model.cells" {
+		t.Fatalf("unexpected input %v", mapped["input"])
+	}
+	fields := mapped["in"].(bson.M)["$arrayToObject"].(bson.M)["$map"].(bson.M)["in"].(bson.M)
+	branches := fields["k"].(bson.M)["$switch"].(bson.M)["branches"].(bson.A)
+	renamed := map[string]string{}
+	for _, branch := range branches {
+		legacy := branch.(bson.M)["case"].(bson.M)["$eq"].(bson.A)[1].(string)
+		renamed[legacy] = branch.(bson.M)["then"].(string)
+	}
+	if len(renamed) != len(legacyCellFields) {
+		t.Fatalf("unexpected branches %v", renamed)
+	}
+	for legacy, name := range legacyCellFields {
+		if renamed[legacy] != name {
+			t.Fatalf("%s renamed to %s instead of %s", legacy, renamed[legacy], name)
+		}
+	}
+	if fields["v"] != "$$field.v" {
+		t.Fatalf("values are changed: %v", fields["v"])
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	cfg, err := config.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	perm, err := permV2Client.NewTestClient(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newMongoBackend(t, cfg, perm).(*MongoRepo)
+	ctx := t.Context()
+
+	legacyCells := bson.A{
+		bson.M{"id": "a", "operatorid": "op", "deploymenttype": "cloud", "inports": bson.A{"in"}, "outports": bson.A{"out"}},
+		bson.M{"id": "b", "type": "link"},
+	}
+	_, err = r.flows.InsertMany(ctx, []interface{}{
+		bson.M{"name": "legacy", "userid": "owner", "model": bson.M{"cells": legacyCells}},
+		bson.M{"name": "current", "userId": "owner", "model": bson.M{"cells": bson.A{bson.M{"id": "a", "operatorId": "op"}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.revisions.InsertOne(ctx, bson.M{"flowId": "legacy", "model": bson.M{"cells": legacyCells}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int64{1, 2}
+	for i, m := range migrations {
+		pending, err := m.run(ctx, r, true)
+		if err != nil {
+			t.Fatalf("migration %d dry run: %v", m.version, err)
+		}
+		affected, err := m.run(ctx, r, false)
+		if err != nil {
+			t.Fatalf("migration %d: %v", m.version, err)
+		}
+		if pending != expected[i] || affected != pending {
+			t.Fatalf("migration %d: dry run counted %d, run changed %d, expected %d", m.version, pending, affected, expected[i])
+		}
+		pending, err = m.run(ctx, r, true)
+		if err != nil || pending != 0 {
+			t.Fatalf("migration %d is not done, %d pending documents: %v", m.version, pending, err)
+		}
+	}
+
+	var flow bson.M
+	err = r.flows.FindOne(ctx, bson.M{"name": "legacy"}).Decode(&flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell := flow["model"].(bson.M)["cells"].(bson.A)[0].(bson.M)
+	if flow["userId"] != "owner" || cell["operatorId"] != "op" || cell["deploymentType"] != "cloud" ||
+		cell["inPorts"] == nil || cell["outPorts"] == nil || cell["operatorid"] != nil {
+		t.Fatalf("unexpected migrated flow %v", flow)
+	}
+
+	err = r.Migrate(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := r.migrations.CountDocuments(ctx, bson.M{})
+	if err != nil || count != int64(len(migrations)) {
+		t.Fatalf("expected %d migration records, got %d: %v", len(migrations), count, err)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
+)
+
+// callWithTimeout runs a call of a client without context support and counts it for the given metrics target.
+// If ctx is done or the timeout expires first, the context error is returned and the result of the abandoned call is discarded.
+// The abandoned call keeps running, so a write may still be applied after its timeout error was returned.
+func callWithTimeout[T any](ctx context.Context, timeout time.Duration, target string, call func() (T, error)) (value T, err error) {
+	defer func() {
+		metrics.CountOutboundCall(target, err)
+	}()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		done <- result{value, err}
+	}()
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
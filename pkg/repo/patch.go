@@ -0,0 +1,58 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+func applyPatch(flow lib.Flow, contentType string, patch []byte) (patched lib.Flow, err error) {
+	doc, err := json.Marshal(flow)
+	if err != nil {
+		return
+	}
+	switch contentType {
+	case lib.MergePatchContentType:
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case lib.JSONPatchContentType:
+		var p jsonpatch.Patch
+		p, err = jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return
+		}
+		doc, err = p.Apply(doc)
+	default:
+		err = errors.New("unsupported patch content type " + contentType)
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(doc, &patched)
+	return
+}
+
+// modelChanged compares the JSON representation of two models, so nil and empty values are treated the same way as in a patched document.
+func modelChanged(a, b lib.Model) bool {
+	aJson, errA := json.Marshal(a)
+	bJson, errB := json.Marshal(b)
+	return errA != nil || errB != nil || !bytes.Equal(aJson, bJson)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+func TestApplyPatch(t *testing.T) {
+	description := "d"
+	flow := lib.Flow{Name: "a", Description: &description, Model: lib.Model{Cells: []lib.Cell{testNode("n")}}}
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		check       func(flow lib.Flow) bool
+		valid       bool
+	}{
+		{"merge patch", lib.MergePatchContentType, `{"name":"b"}`, func(f lib.Flow) bool {
+			return f.Name == "b" && f.Description != nil && *f.Description == "d" && len(f.Model.Cells) == 1
+		}, true},
+		{"merge patch removes field", lib.MergePatchContentType, `{"description":null}`, func(f lib.Flow) bool {
+			return f.Name == "a" && f.Description == nil
+		}, true},
+		{"json patch", lib.JSONPatchContentType, `[{"op":"replace","path":"/model/cells/0/id","value":"m"}]`, func(f lib.Flow) bool {
+			return f.Name == "a" && f.Model.Cells[0].Id == "m"
+		}, true},
+		{"json patch on missing path", lib.JSONPatchContentType, `[{"op":"replace","path":"/model/cells/1/id","value":"m"}]`, nil, false},
+		{"invalid patch", lib.MergePatchContentType, `{`, nil, false},
+		{"unsupported content type", "application/json", `{"name":"b"}`, nil, false},
+	}
+	for _, test := range tests {
+		patched, err := applyPatch(flow, test.contentType, []byte(test.patch))
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+		if test.valid && !test.check(patched) {
+			t.Fatalf("%s: unexpected flow %+v", test.name, patched)
+		}
+	}
+	if flow.Name != "a" || *flow.Description != "d" || flow.Model.Cells[0].Id != "n" {
+		t.Fatalf("patch changed the original flow %+v", flow)
+	}
+}
+
+func TestModelChanged(t *testing.T) {
+	if modelChanged(lib.Model{}, lib.Model{Cells: []lib.Cell{}}) {
+		t.Fatal("expected equal empty models")
+	}
+	if !modelChanged(lib.Model{Cells: []lib.Cell{testNode("a")}}, lib.Model{Cells: []lib.Cell{testNode("b")}}) {
+		t.Fatal("expected changed model")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var projectionFields = map[string]string{
+	"name":        "name",
+	"description": "description",
+	"model":       "model",
+	"image":       "image",
+	"userId":      "userId",
+	"dateCreated": "dateCreated",
+	"dateUpdated": "dateUpdated",
+	"dateDeleted": "dateDeleted",
+	"version":     "version",
+	"tags":        "tags",
+}
+
+// parseProjection maps a comma separated list of flow fields to a mongo projection. The id is always included,
+// so the projection is never empty.
+func parseProjection(fields string) (projection bson.M, err error) {
+	projection = bson.M{"_id": 1}
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || field == "_id" {
+			continue
+		}
+		path, ok := projectionFields[field]
+		if !ok {
+			return nil, lib.NewInputError(errors.New("unknown field " + field))
+		}
+		projection[path] = 1
+	}
+	return
+}
+
+// summaryStage adds the number of nodes and links of the model to each flow.
+func summaryStage() bson.M {
+	cells := bson.M{"$ifNull": bson.A{"$model.cells", bson.A{}}}
+	links := bson.M{"$size": bson.M{"$filter": bson.M{
+		"input": cells,
+		"as":    "cell",
+		"cond": bson.M{"$or": bson.A{
+			bson.M{"$ne": bson.A{bson.M{"$type": "$$cell.source"}, "missing"}},
+			bson.M{"$ne": bson.A{bson.M{"$type": "$$cell.target"}, "missing"}},
+		}},
+	}}}
+	return bson.M{"$addFields": bson.M{"summary": bson.M{
+		"links": links,
+		"nodes": bson.M{"$subtract": bson.A{bson.M{"$size": cells}, links}},
+	}}}
+}
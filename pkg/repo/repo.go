@@ -20,65 +20,168 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/config"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/events"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/metrics"
 	operator_api "github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/operator-api"
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/pkg/util"
+	operator_repo "github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
 	pipelinesClient "github.com/SENERGY-Platform/analytics-pipeline/client"
+	pipelineLib "github.com/SENERGY-Platform/analytics-pipeline/lib"
 	srv_info_hdl "github.com/SENERGY-Platform/go-service-base/srv-info-hdl"
-	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"golang.org/x/sync/errgroup"
 )
 
 type Repo struct {
+	cfg          *config.Config
 	srvInfoHdl   srv_info_hdl.Handler
 	dbRepo       FlowRepository
 	operatorRepo *operator_api.Repo
 	pipe         pipelinesClient.Client
+	httpClient   *http.Client
+	events       events.Publisher
+	eventsNotify chan struct{}
 }
 
-func New(srvInfoHdl srv_info_hdl.Handler, perm permV2Client.Client, operatorRepo *operator_api.Repo, pipe pipelinesClient.Client) (*Repo, error) {
-	dbRepo := NewMongoRepo(perm)
-	err := dbRepo.validateFlowPermissions()
+// New creates the service. Flow events are only recorded if a publisher is given.
+func New(cfg *config.Config, srvInfoHdl srv_info_hdl.Handler, dbRepo FlowRepository, operatorRepo *operator_api.Repo, pipe pipelinesClient.Client, publisher events.Publisher) *Repo {
 	return &Repo{
+		cfg:          cfg,
 		srvInfoHdl:   srvInfoHdl,
 		dbRepo:       dbRepo,
 		operatorRepo: operatorRepo,
 		pipe:         pipe,
-	}, err
+		httpClient:   &http.Client{Timeout: cfg.HttpTimeout},
+		events:       publisher,
+		eventsNotify: make(chan struct{}, 1),
+	}
 }
 
 func (r *Repo) SrvInfo(_ context.Context) srv_info_hdl.ServiceInfo {
 	return r.srvInfoHdl.ServiceInfo()
 }
 
+// HealthCheck always succeeds, the service is alive as long as it responds. See Readiness for the dependencies.
 func (r *Repo) HealthCheck(_ context.Context) error {
 	return nil
 }
 
-func (r *Repo) CreateFlow(flow lib.Flow, userId string, auth string) (id string, err error) {
-	err = r.validateOperators(&flow, userId, auth)
+func (r *Repo) CreateFlow(ctx context.Context, flow lib.Flow, userId string, auth string) (id string, err error) {
+	err = r.validateFlow(ctx, &flow, userId, auth)
 	if err != nil {
 		return
 	}
 	flow.UserId = userId
-	return r.dbRepo.InsertFlow(flow)
+	var revision int64
+	err = r.dbRepo.WithTransaction(ctx, func(ctx context.Context) (err error) {
+		id, revision, err = r.dbRepo.InsertFlow(ctx, flow)
+		if err != nil {
+			return
+		}
+		return r.recordEvent(ctx, lib.FlowCreated, id, userId, revision)
+	})
+	if err != nil {
+		return
+	}
+	r.notifyEventRelay()
+	return
+}
+
+func (r *Repo) UpdateFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64, userId string, auth string) (err error) {
+	err = r.validateFlow(ctx, &flow, userId, auth)
+	if err != nil {
+		return
+	}
+	return r.updateFlow(ctx, id, flow, expectedVersion, userId, auth)
+}
+
+// updateFlow stores a validated flow together with its revision and change event.
+func (r *Repo) updateFlow(ctx context.Context, id string, flow lib.Flow, expectedVersion *int64, userId string, auth string) (err error) {
+	err = r.dbRepo.WithTransaction(ctx, func(ctx context.Context) (err error) {
+		revision, err := r.dbRepo.UpdateFlow(ctx, id, flow, expectedVersion, userId, auth)
+		if err != nil {
+			return
+		}
+		return r.recordEvent(ctx, lib.FlowUpdated, id, userId, revision)
+	})
+	if err != nil {
+		return
+	}
+	r.notifyEventRelay()
+	return
+}
+
+// PatchFlow applies a JSON merge patch (RFC 7396) or JSON patch (RFC 6902) to a stored flow.
+// Operators are only validated again if the patch changed the model.
+func (r *Repo) PatchFlow(ctx context.Context, id string, contentType string, patch []byte, expectedVersion *int64, userId string, auth string) (err error) {
+	flow, err := r.dbRepo.FindFlow(ctx, id, userId, auth)
+	if err != nil {
+		return
+	}
+	if expectedVersion == nil {
+		expectedVersion = &flow.Version
+	}
+	patched, err := applyPatch(flow, contentType, patch)
+	if err != nil {
+		return lib.NewInputError(err)
+	}
+	patched.Id = flow.Id
+	patched.UserId = flow.UserId
+	patched.DateCreated = flow.DateCreated
+	patched.DateDeleted = flow.DateDeleted
+	if modelChanged(flow.Model, patched.Model) {
+		err = r.validateFlow(ctx, &patched, userId, auth)
+		if err != nil {
+			return
+		}
+	}
+	return r.updateFlow(ctx, id, patched, expectedVersion, userId, auth)
 }
 
-func (r *Repo) UpdateFlow(id string, flow lib.Flow, userId string, auth string) (err error) {
-	err = r.validateOperators(&flow, userId, auth)
+// CloneFlow copies a readable flow into a new flow owned by the caller.
+func (r *Repo) CloneFlow(ctx context.Context, id, userId, auth string) (cloneId string, err error) {
+	flow, err := r.dbRepo.FindFlow(ctx, id, userId, auth)
 	if err != nil {
 		return
 	}
-	return r.dbRepo.UpdateFlow(id, flow, userId, auth)
+	clone := lib.Flow{
+		Name:        flow.Name + r.cfg.CloneNameSuffix,
+		Description: flow.Description,
+		Model:       flow.Model,
+		Image:       flow.Image,
+		Tags:        flow.Tags,
+	}
+	return r.CreateFlow(ctx, clone, userId, auth)
 }
 
-func (r *Repo) validateOperators(flow *lib.Flow, userId string, auth string) error {
+func (r *Repo) validateFlow(ctx context.Context, flow *lib.Flow, userId string, auth string) (err error) {
+	err = validateModel(flow.Model)
+	if err != nil {
+		return
+	}
+	if r.cfg.StrictFlowAnalysis {
+		err = validateTopology(flow.Model)
+		if err != nil {
+			return
+		}
+	}
+	return r.validateOperators(ctx, flow, userId, auth)
+}
+
+func (r *Repo) validateOperators(ctx context.Context, flow *lib.Flow, userId string, auth string) error {
+	operators, err := r.getOperators(ctx, operatorIds(flow.Model), userId, auth)
+	if err != nil {
+		return err
+	}
 	for i, operator := range flow.Model.Cells {
-		if operator.Type == "senergy.NodeElement" {
-			op, err := r.operatorRepo.GetOperator(*operator.OperatorId, userId, auth)
-			if err != nil {
-				return lib.NewExternalResourceError(err)
-			}
+		if operator.Type == NodeElementType {
+			op := operators[*operator.OperatorId]
 			operator.Name = &op.Name
 			operator.Image = &op.Image
 			operator.DeploymentType = &op.DeploymentType
@@ -91,28 +194,210 @@ func (r *Repo) validateOperators(flow *lib.Flow, userId string, auth string) err
 	return nil
 }
 
-func (r *Repo) DeleteFlow(id, userId, auth string) (err error) {
-	usage, err, code := r.pipe.GetFlowUsageById(auth, userId, id)
+func (r *Repo) DeleteFlow(ctx context.Context, id, userId, auth string) (err error) {
+	res, err := callWithTimeout(ctx, r.cfg.HttpTimeout, metrics.TargetPipelineRegistry, func() (flowUsage, error) {
+		usage, err, code := r.pipe.GetFlowUsageById(auth, userId, id)
+		return flowUsage{usage, code}, err
+	})
 	if err != nil {
 		return lib.NewExternalResourceError(err)
 	}
-	if code != http.StatusOK {
-		if code == http.StatusNoContent {
-			return r.dbRepo.DeleteFlow(id, userId, false, auth)
+	if res.code != http.StatusOK {
+		if res.code == http.StatusNoContent {
+			err = r.dbRepo.WithTransaction(ctx, func(ctx context.Context) (err error) {
+				err = r.dbRepo.DeleteFlow(ctx, id, userId, false, auth)
+				if err != nil {
+					return
+				}
+				return r.recordEvent(ctx, lib.FlowDeleted, id, userId, 0)
+			})
+			if err != nil {
+				return
+			}
+			r.notifyEventRelay()
+			return
 		}
-		return lib.NewExternalResourceError(errors.New("pipeline registry error, wrong status code " + strconv.Itoa(code)))
+		return lib.NewExternalResourceError(errors.New("pipeline registry error, wrong status code " + strconv.Itoa(res.code)))
+	}
+	return lib.NewStillInUseError(res.usage, errors.New("flow still in use"))
+}
+
+type flowUsage struct {
+	usage *pipelineLib.FlowUsage
+	code  int
+}
+
+// getOperators fetches each operator once, with at most the configured number of parallel requests.
+func (r *Repo) getOperators(ctx context.Context, ids []string, userId, auth string) (map[string]operator_repo.Operator, error) {
+	operators := make(map[string]operator_repo.Operator, len(ids))
+	mux := sync.Mutex{}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(r.cfg.OperatorFetchConcurrency, 1))
+	for _, id := range ids {
+		g.Go(func() error {
+			op, err := r.operatorRepo.GetOperator(gctx, id, userId, auth)
+			if err != nil {
+				return err
+			}
+			mux.Lock()
+			operators[id] = op
+			mux.Unlock()
+			return nil
+		})
+	}
+	return operators, g.Wait()
+}
+
+// operatorIds returns the distinct operator ids of all nodes in order of their first occurrence.
+func operatorIds(model lib.Model) (ids []string) {
+	for _, cell := range model.Cells {
+		if cell.Type == NodeElementType && cell.OperatorId != nil && !slices.Contains(ids, *cell.OperatorId) {
+			ids = append(ids, *cell.OperatorId)
+		}
+	}
+	return
+}
+
+func (r *Repo) GetOperatorCacheStats(_ context.Context) lib.OperatorCacheStats {
+	return r.operatorRepo.CacheStats()
+}
+
+func (r *Repo) ClearOperatorCache(_ context.Context) {
+	r.operatorRepo.ClearCache()
+}
+
+func (r *Repo) GetTags(ctx context.Context, userId, auth string) (tags []lib.TagCount, err error) {
+	return r.dbRepo.AllTags(ctx, userId, auth)
+}
+
+func (r *Repo) GetDeletedFlows(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
+	return r.dbRepo.AllDeleted(ctx, userId, args, auth)
+}
+
+func (r *Repo) RestoreFlow(ctx context.Context, id, userId, auth string) (err error) {
+	err = r.dbRepo.WithTransaction(ctx, func(ctx context.Context) (err error) {
+		err = r.dbRepo.RestoreFlow(ctx, id, userId, auth)
+		if err != nil {
+			return
+		}
+		return r.recordEvent(ctx, lib.FlowRestored, id, userId, 0)
+	})
+	if err != nil {
+		return
+	}
+	r.notifyEventRelay()
+	return
+}
+
+// RunTrashPurge periodically removes flows which are longer in the trash than the configured retention, until ctx is done.
+func (r *Repo) RunTrashPurge(ctx context.Context) {
+	if r.cfg.TrashPurgeInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.TrashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		count, err := r.dbRepo.PurgeFlows(ctx, time.Now().Add(-r.cfg.TrashRetention))
+		if err != nil {
+			util.Logger.Error("error purging deleted flows", "error", err)
+		} else if count > 0 {
+			util.Logger.Info("purged deleted flows", "count", count)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunFlowMetrics periodically updates the flow count gauges, until ctx is done.
+func (r *Repo) RunFlowMetrics(ctx context.Context) {
+	if r.cfg.FlowMetricsInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.FlowMetricsInterval)
+	defer ticker.Stop()
+	for {
+		err := r.updateFlowMetrics(ctx)
+		if err != nil {
+			util.Logger.Error("error updating flow metrics", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Repo) updateFlowMetrics(ctx context.Context) error {
+	count, err := r.dbRepo.CountFlows(ctx)
+	if err != nil {
+		return err
+	}
+	mapping, err := r.dbRepo.GetOperatorFlowMapping(ctx)
+	if err != nil {
+		return err
+	}
+	operatorFlows := make(map[string]int, len(mapping))
+	for _, operator := range mapping {
+		operatorFlows[operator.OperatorID] = len(operator.Flows)
+	}
+	metrics.SetFlows(count)
+	metrics.SetOperatorFlows(operatorFlows)
+	return nil
+}
+
+func (r *Repo) GetFlows(ctx context.Context, userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
+	return r.dbRepo.All(ctx, userId, false, args, auth)
+}
+
+func (r *Repo) GetFlow(ctx context.Context, flowId, userId, auth string) (response lib.Flow, err error) {
+	return r.dbRepo.FindFlow(ctx, flowId, userId, auth)
+}
+
+func (r *Repo) GetFlowAnalysis(ctx context.Context, flowId, userId, auth string) (analysis lib.FlowAnalysis, err error) {
+	flow, err := r.dbRepo.FindFlow(ctx, flowId, userId, auth)
+	if err != nil {
+		return
+	}
+	return analyzeModel(flow.Model), nil
+}
+
+func (r *Repo) GetFlowRevisions(ctx context.Context, flowId, userId string, args map[string][]string, auth string) (response lib.FlowRevisionsResponse, err error) {
+	return r.dbRepo.AllRevisions(ctx, flowId, userId, args, auth)
+}
+
+func (r *Repo) GetFlowRevision(ctx context.Context, flowId string, revision int64, userId, auth string) (response lib.FlowRevision, err error) {
+	return r.dbRepo.FindRevision(ctx, flowId, revision, userId, auth)
+}
+
+func (r *Repo) RestoreFlowRevision(ctx context.Context, flowId string, revision int64, userId, auth string) (err error) {
+	flowRevision, err := r.dbRepo.FindRevision(ctx, flowId, revision, userId, auth)
+	if err != nil {
+		return
+	}
+	flow, err := r.dbRepo.FindFlow(ctx, flowId, userId, auth)
+	if err != nil {
+		return
+	}
+	flow.Name = flowRevision.Name
+	flow.Description = flowRevision.Description
+	if flowRevision.Model != nil {
+		flow.Model = *flowRevision.Model
 	}
-	return lib.NewStillInUseError(usage, errors.New("flow still in use"))
+	return r.UpdateFlow(ctx, flowId, flow, nil, userId, auth)
 }
 
-func (r *Repo) GetFlows(userId string, args map[string][]string, auth string) (response lib.FlowsResponse, err error) {
-	return r.dbRepo.All(userId, false, args, auth)
+func (r *Repo) GetFlowPermissions(ctx context.Context, flowId, userId, auth string) (permissions lib.FlowPermissions, err error) {
+	return r.dbRepo.GetPermissions(ctx, flowId, userId, auth)
 }
 
-func (r *Repo) GetFlow(flowId, userId, auth string) (response lib.Flow, err error) {
-	return r.dbRepo.FindFlow(flowId, userId, auth)
+func (r *Repo) SetFlowPermissions(ctx context.Context, flowId string, permissions lib.FlowPermissions, userId, auth string) (result lib.FlowPermissions, err error) {
+	return r.dbRepo.SetPermissions(ctx, flowId, permissions, userId, auth)
 }
 
-func (r *Repo) GetOperatorUsage() ([]lib.OperatorFlowCount, error) {
-	return r.dbRepo.GetOperatorFlowMapping()
+func (r *Repo) GetOperatorUsage(ctx context.Context) ([]lib.OperatorFlowCount, error) {
+	return r.dbRepo.GetOperatorFlowMapping(ctx)
 }
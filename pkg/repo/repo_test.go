@@ -0,0 +1,571 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func flowNames(flows []lib.Flow) (names []string) {
+	for _, flow := range flows {
+		names = append(names, flow.Name)
+	}
+	return
+}
+
+func TestListSortAndCursor(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		for _, name := range []string{"c", "a", "b"} {
+			createFlow(t, env, lib.Flow{Name: name}, "owner")
+		}
+
+		res, err := env.repo.GetFlows(t.Context(), "owner", map[string][]string{"sort": {"name:desc"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(flowNames(res.Flows), []string{"c", "b", "a"}) || res.Total != 3 {
+			t.Fatalf("unexpected flows %v, total %d", flowNames(res.Flows), res.Total)
+		}
+
+		res, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"sort": {"name:asc"}, "limit": {"1"}, "offset": {"1"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(flowNames(res.Flows), []string{"b"}) || res.Total != 3 {
+			t.Fatalf("unexpected flows %v, total %d", flowNames(res.Flows), res.Total)
+		}
+
+		res, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"sort": {"name:asc"}, "limit": {"2"}, "total": {"false"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(flowNames(res.Flows), []string{"a", "b"}) || res.Total != -1 {
+			t.Fatalf("unexpected flows %v, total %d", flowNames(res.Flows), res.Total)
+		}
+
+		var names []string
+		cursor := ""
+		for page := 0; ; page++ {
+			if page > 3 {
+				t.Fatal("cursor does not terminate")
+			}
+			res, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"sort": {"name:asc"}, "limit": {"2"}, "cursor": {cursor}}, auth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, flowNames(res.Flows)...)
+			if res.NextCursor == "" {
+				break
+			}
+			cursor = res.NextCursor
+		}
+		if !slices.Equal(names, []string{"a", "b", "c"}) {
+			t.Fatalf("unexpected flows %v", names)
+		}
+
+		_, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"sort": {"name:desc"}, "cursor": {cursor}}, auth)
+		if !errors.As(err, new(*lib.InputError)) {
+			t.Fatalf("expected input error for a cursor of another sort order, got %v", err)
+		}
+	})
+}
+
+func TestListSearchAndFilters(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		op := testOperator("filter")
+		env := newEnv(op)
+		auth := testToken("owner")
+		createFlow(t, env, lib.Flow{Name: "temperature alert", Tags: []string{"alerts"}}, "owner")
+		description := "warns before the temperature drops below the dew point"
+		createFlow(t, env, lib.Flow{Name: "humidity", Description: &description, Tags: []string{"alerts", "climate"}, Model: testModel(op)}, "owner")
+		createFlow(t, env, lib.Flow{Name: "temperature of other user"}, "other")
+
+		yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+		tests := []struct {
+			name string
+			args map[string][]string
+			want []string
+		}{
+			{"search ranked by relevance", map[string][]string{"search": {"temperature"}}, []string{"temperature alert", "humidity"}},
+			{"search sorted by name", map[string][]string{"search": {"temperature"}, "sort": {"name:asc"}}, []string{"humidity", "temperature alert"}},
+			{"search in operator names", map[string][]string{"search": {"filter"}}, []string{"humidity"}},
+			{"tag", map[string][]string{"filter": {"tag:alerts"}, "sort": {"name:asc"}}, []string{"humidity", "temperature alert"}},
+			{"tags", map[string][]string{"filter": {"tag:climate|tag:alerts"}}, []string{"humidity"}},
+			{"operator", map[string][]string{"filter": {"operator:" + op.Id.Hex()}}, []string{"humidity"}},
+			{"deployment type", map[string][]string{"filter": {"deploymentType:cloud"}}, []string{"humidity"}},
+			{"owner", map[string][]string{"filter": {"owner:other"}}, nil},
+			{"created after", map[string][]string{"filter": {"dateCreated:after=" + yesterday}, "sort": {"name:asc"}}, []string{"humidity", "temperature alert"}},
+			{"created before", map[string][]string{"filter": {"dateCreated:before=" + yesterday}}, nil},
+			{"updated in range", map[string][]string{"filter": {"dateUpdated:after=" + yesterday + ",before=2999-01-01T00:00:00Z"}, "sort": {"name:asc"}}, []string{"humidity", "temperature alert"}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				res, err := env.repo.GetFlows(t.Context(), "owner", test.args, auth)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(flowNames(res.Flows), test.want) || res.Total != int64(len(test.want)) {
+					t.Fatalf("expected %v, got %v with total %d", test.want, flowNames(res.Flows), res.Total)
+				}
+			})
+		}
+
+		for _, filter := range []string{"unknown:x", "tag", "dateCreated:after=yesterday", "dateCreated:since=2020-01-01"} {
+			_, err := env.repo.GetFlows(t.Context(), "owner", map[string][]string{"filter": {filter}}, auth)
+			if !errors.As(err, new(*lib.InputError)) {
+				t.Fatalf("expected input error for filter %s, got %v", filter, err)
+			}
+		}
+	})
+}
+
+func TestListProjection(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		op := testOperator("projection")
+		env := newEnv(op)
+		auth := testToken("owner")
+		id := createFlow(t, env, lib.Flow{Name: "a", Model: testModel(op), Tags: []string{"x"}}, "owner")
+
+		res, err := env.repo.GetFlows(t.Context(), "owner", map[string][]string{"fields": {"name,tags"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Flows) != 1 || res.Flows[0].Id.Hex() != id || res.Flows[0].Name != "a" || len(res.Flows[0].Tags) != 1 ||
+			res.Flows[0].UserId != "" || len(res.Flows[0].Model.Cells) != 0 {
+			t.Fatalf("unexpected projected flows %+v", res.Flows)
+		}
+
+		for _, fields := range []string{"_id", ""} {
+			res, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"fields": {fields}}, auth)
+			if err != nil {
+				t.Fatalf("fields %q: %v", fields, err)
+			}
+			if len(res.Flows) != 1 || res.Flows[0].Id.Hex() != id || res.Flows[0].Name != "" || res.Total != 1 {
+				t.Fatalf("fields %q: expected bare ids, got %+v", fields, res.Flows)
+			}
+		}
+
+		res, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"fields": {"name"}, "summary": {"true"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Flows) != 1 || res.Flows[0].Summary == nil || res.Flows[0].Summary.Nodes != 1 || len(res.Flows[0].Model.Cells) != 0 {
+			t.Fatalf("unexpected summary %+v", res.Flows)
+		}
+
+		_, err = env.repo.GetFlows(t.Context(), "owner", map[string][]string{"fields": {"unknown"}}, auth)
+		if !errors.As(err, new(*lib.InputError)) {
+			t.Fatalf("expected input error for an unknown field, got %v", err)
+		}
+	})
+}
+
+func TestUpdateVersionMismatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		id := createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+		flow, err := env.repo.GetFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		version := flow.Version
+		err = env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: "b"}, &version, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: "c"}, &version, "owner", auth)
+		if !errors.As(err, new(*lib.PreconditionFailedError)) {
+			t.Fatalf("expected precondition failed error, got %v", err)
+		}
+		err = env.repo.PatchFlow(t.Context(), id, lib.MergePatchContentType, []byte(`{"name":"c"}`), &version, "owner", auth)
+		if !errors.As(err, new(*lib.PreconditionFailedError)) {
+			t.Fatalf("expected precondition failed error, got %v", err)
+		}
+
+		flow, err = env.repo.GetFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flow.Name != "b" || flow.Version != version+1 {
+			t.Fatalf("unexpected flow %s in version %d", flow.Name, flow.Version)
+		}
+	})
+}
+
+func TestTrashRestorePurge(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		id := createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+
+		err := env.repo.DeleteFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = env.repo.GetFlow(t.Context(), id, "owner", auth)
+		if !isNotFound(err) {
+			t.Fatalf("expected trashed flow to be not found, got %v", err)
+		}
+		assertFlowNames(t, env, "owner", []string{}, []string{"a"})
+
+		err = env.repo.RestoreFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFlowNames(t, env, "owner", []string{"a"}, []string{})
+
+		err = env.repo.DeleteFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := env.db.PurgeFlows(t.Context(), time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Fatalf("expected recently trashed flow to be kept, purged %d", count)
+		}
+		count, err = env.db.PurgeFlows(t.Context(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Fatalf("expected 1 purged flow, got %d", count)
+		}
+		assertFlowNames(t, env, "owner", []string{}, []string{})
+		err = env.repo.RestoreFlow(t.Context(), id, "owner", auth)
+		if err == nil {
+			t.Fatal("expected purged flow to not be restorable")
+		}
+	})
+}
+
+func assertFlowNames(t *testing.T, env testEnv, userId string, flows, trash []string) {
+	t.Helper()
+	res, err := env.repo.GetFlows(t.Context(), userId, map[string][]string{}, testToken(userId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := flowNames(res.Flows); !slices.Equal(names, flows) {
+		t.Fatalf("expected flows %v, got %v", flows, names)
+	}
+	res, err = env.repo.GetDeletedFlows(t.Context(), userId, map[string][]string{}, testToken(userId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := flowNames(res.Flows); !slices.Equal(names, trash) {
+		t.Fatalf("expected trashed flows %v, got %v", trash, names)
+	}
+}
+
+func TestDateDeletedIsReadOnly(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		op := testOperator("usage")
+		env := newEnv(op)
+		auth := testToken("owner")
+		deleted := time.Now()
+
+		id := createFlow(t, env, lib.Flow{Name: "a", DateDeleted: &deleted, Model: testModel(op)}, "owner")
+		assertFlowNames(t, env, "owner", []string{"a"}, []string{})
+
+		err := env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: "b", DateDeleted: &deleted, Model: testModel(op)}, nil, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFlowNames(t, env, "owner", []string{"b"}, []string{})
+
+		err = env.repo.PatchFlow(t.Context(), id, lib.MergePatchContentType, []byte(`{"dateDeleted":"2020-01-01T00:00:00Z"}`), nil, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFlowNames(t, env, "owner", []string{"b"}, []string{})
+
+		usage, err := env.repo.GetOperatorUsage(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(usage) != 1 || usage[0].OperatorID != op.Id.Hex() {
+			t.Fatalf("unexpected operator usage %+v", usage)
+		}
+		err = env.repo.DeleteFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: "c", Model: testModel(op)}, nil, "owner", auth)
+		if !isNotFound(err) {
+			t.Fatalf("expected trashed flow to not be updatable, got %v", err)
+		}
+		usage, err = env.repo.GetOperatorUsage(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(usage) != 0 {
+			t.Fatalf("expected trashed flows to not count as operator usage, got %+v", usage)
+		}
+	})
+}
+
+func TestUpdateKeepsOwnerAndCreationDate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		id := createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+		created, err := env.repo.GetFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: "b"}, nil, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: "c", UserId: "other", DateCreated: time.Unix(0, 0)}, nil, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.repo.PatchFlow(t.Context(), id, lib.MergePatchContentType, []byte(`{"userId":"other","dateCreated":"2020-01-01T00:00:00Z"}`), nil, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flow, err := env.repo.GetFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flow.UserId != "owner" || !flow.DateCreated.Equal(created.DateCreated) || flow.Id.Hex() != id {
+			t.Fatalf("expected owner, creation date and id to be kept, got %+v", flow)
+		}
+		res, err := env.repo.GetFlows(t.Context(), "owner", map[string][]string{"filter": {"owner:owner"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Flows) != 1 {
+			t.Fatalf("expected flow to be listed for its owner, got %v", flowNames(res.Flows))
+		}
+	})
+}
+
+func TestTags(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		createFlow(t, env, lib.Flow{Name: "temperature alert", Tags: []string{" alerts", "alerts", ""}}, "owner")
+		createFlow(t, env, lib.Flow{Name: "humidity", Tags: []string{"alerts", "climate"}}, "owner")
+		createFlow(t, env, lib.Flow{Name: "untagged"}, "owner")
+		createFlow(t, env, lib.Flow{Name: "other", Tags: []string{"alerts", "other"}}, "other")
+
+		tests := []struct {
+			name string
+			args map[string][]string
+			want []string
+		}{
+			{"tag", map[string][]string{"filter": {"tag:alerts"}, "sort": {"name:asc"}}, []string{"humidity", "temperature alert"}},
+			{"any tag", map[string][]string{"filter": {"tag:climate,other"}}, []string{"humidity"}},
+			{"all tags", map[string][]string{"filter": {"tag:climate|tag:alerts"}}, []string{"humidity"}},
+			{"unknown tag", map[string][]string{"filter": {"tag:unknown"}}, nil},
+		}
+		for _, test := range tests {
+			res, err := env.repo.GetFlows(t.Context(), "owner", test.args, auth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(flowNames(res.Flows), test.want) || res.Total != int64(len(test.want)) {
+				t.Fatalf("%s: expected %v, got %v with total %d", test.name, test.want, flowNames(res.Flows), res.Total)
+			}
+		}
+
+		tags, err := env.repo.GetTags(t.Context(), "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(tags, []lib.TagCount{{Tag: "alerts", Count: 2}, {Tag: "climate", Count: 1}}) {
+			t.Fatalf("unexpected tags %+v", tags)
+		}
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		id := createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+		for _, name := range []string{"b", "c"} {
+			err := env.repo.UpdateFlow(t.Context(), id, lib.Flow{Name: name}, nil, "owner", auth)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		res, err := env.repo.GetFlowRevisions(t.Context(), id, "owner", map[string][]string{"limit": {"2"}}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 3 || len(res.Revisions) != 2 || res.Revisions[0].Revision != 3 || res.Revisions[0].Name != "c" {
+			t.Fatalf("unexpected revisions %+v", res)
+		}
+
+		revision, err := env.repo.GetFlowRevision(t.Context(), id, 1, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revision.Name != "a" || revision.Author != "owner" {
+			t.Fatalf("unexpected revision %+v", revision)
+		}
+		_, err = env.repo.GetFlowRevision(t.Context(), id, 4, "owner", auth)
+		if !isNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+
+		err = env.repo.RestoreFlowRevision(t.Context(), id, 1, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		flow, err := env.repo.GetFlow(t.Context(), id, "owner", auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flow.Name != "a" {
+			t.Fatalf("expected restored name a, got %s", flow.Name)
+		}
+		res, err = env.repo.GetFlowRevisions(t.Context(), id, "owner", map[string][]string{}, auth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 4 || res.Revisions[0].Name != "a" {
+			t.Fatalf("expected the restore to add revision 4, got %+v", res)
+		}
+
+		_, err = env.repo.GetFlowRevisions(t.Context(), id, "other", map[string][]string{}, testToken("other"))
+		if err == nil {
+			t.Fatal("expected revisions to be hidden from other users")
+		}
+	})
+}
+
+func TestInvalidListArguments(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		env := newEnv()
+		auth := testToken("owner")
+		id := createFlow(t, env, lib.Flow{Name: "a"}, "owner")
+
+		for _, args := range []map[string][]string{{"limit": {"abc"}}, {"offset": {"abc"}}} {
+			_, err := env.repo.GetFlows(t.Context(), "owner", args, auth)
+			if !errors.As(err, new(*lib.InputError)) {
+				t.Fatalf("flows %v: expected input error, got %v", args, err)
+			}
+			_, err = env.repo.GetDeletedFlows(t.Context(), "owner", args, auth)
+			if !errors.As(err, new(*lib.InputError)) {
+				t.Fatalf("trash %v: expected input error, got %v", args, err)
+			}
+			_, err = env.repo.GetFlowRevisions(t.Context(), id, "owner", args, auth)
+			if !errors.As(err, new(*lib.InputError)) {
+				t.Fatalf("revisions %v: expected input error, got %v", args, err)
+			}
+		}
+	})
+}
+
+func TestGetOperators(t *testing.T) {
+	a, b := testOperator("a"), testOperator("b")
+	env := newTestEnv(t, a, b)
+	ctx := t.Context()
+	aId, bId := a.Id.Hex(), b.Id.Hex()
+	model := lib.Model{Cells: []lib.Cell{
+		{Id: "a1", Type: NodeElementType, OperatorId: &aId},
+		{Id: "a2", Type: NodeElementType, OperatorId: &aId},
+		{Id: "a3", Type: NodeElementType, OperatorId: &aId},
+		{Id: "b1", Type: NodeElementType, OperatorId: &bId},
+	}}
+
+	steps := []struct {
+		name     string
+		userId   string
+		clear    bool
+		requests int64
+	}{
+		{"distinct operators", "owner", false, 2},
+		{"cached", "owner", false, 2},
+		{"other user", "other", false, 4},
+		{"cleared", "owner", true, 6},
+	}
+	for _, step := range steps {
+		if step.clear {
+			env.repo.ClearOperatorCache(ctx)
+		}
+		createFlow(t, env, lib.Flow{Name: step.name, Model: model}, step.userId)
+		if requests := env.operatorRequests.Load(); requests != step.requests {
+			t.Fatalf("%s: expected %d requests to the operator repo, got %d", step.name, step.requests, requests)
+		}
+	}
+	stats := env.repo.GetOperatorCacheStats(ctx)
+	if stats.Hits != 2 || stats.Misses != 6 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	missing := bson.NewObjectID().Hex()
+	_, err := env.repo.CreateFlow(ctx, lib.Flow{Name: "missing", Model: lib.Model{Cells: []lib.Cell{
+		{Id: "a1", Type: NodeElementType, OperatorId: &aId},
+		{Id: "m1", Type: NodeElementType, OperatorId: &missing},
+	}}}, "owner", testToken("owner"))
+	if !errors.As(err, new(*lib.InputError)) || !strings.Contains(err.Error(), missing) {
+		t.Fatalf("expected input error naming %s, got %v", missing, err)
+	}
+}
+
+func TestFlowMetricsExcludeTrash(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, newEnv newEnvFunc) {
+		op := testOperator("metrics")
+		env := newEnv(op)
+		ctx := t.Context()
+		createFlow(t, env, lib.Flow{Name: "a", Model: testModel(op)}, "owner")
+		id := createFlow(t, env, lib.Flow{Name: "b", Model: testModel(op)}, "owner")
+		err := env.repo.DeleteFlow(ctx, id, "owner", testToken("owner"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = env.repo.updateFlowMetrics(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := fmt.Sprintf(`# HELP analytics_flow_repo_flows Number of flows which are not deleted.
+# TYPE analytics_flow_repo_flows gauge
+analytics_flow_repo_flows 1
+# HELP analytics_flow_repo_operator_flows Number of flows using an operator.
+# TYPE analytics_flow_repo_operator_flows gauge
+analytics_flow_repo_operator_flows{operator_id="%s"} 1
+`, op.Id.Hex())
+		err = testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "analytics_flow_repo_flows", "analytics_flow_repo_operator_flows")
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
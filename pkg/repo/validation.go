@@ -0,0 +1,92 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+func isLink(cell lib.Cell) bool {
+	return cell.Source != nil || cell.Target != nil
+}
+
+// validateModel checks the structure of a flow model. Every cell needs a unique id and
+// every link has to connect an output port of an existing node with an input port of an existing node.
+// All problems are collected and returned as a single lib.ModelError.
+func validateModel(model lib.Model) error {
+	var problems []lib.ModelProblem
+	nodes := map[string]lib.Cell{}
+	seen := map[string]bool{}
+	for _, cell := range model.Cells {
+		if cell.Id == "" {
+			problems = append(problems, lib.ModelProblem{CellId: cell.Id, Code: lib.ProblemMissingId, Message: "missing id"})
+			continue
+		}
+		if seen[cell.Id] {
+			problems = append(problems, lib.ModelProblem{CellId: cell.Id, Code: lib.ProblemDuplicateId, Message: "duplicate id"})
+			continue
+		}
+		seen[cell.Id] = true
+		if !isLink(cell) {
+			nodes[cell.Id] = cell
+		}
+		if cell.Type == NodeElementType && (cell.OperatorId == nil || *cell.OperatorId == "") {
+			problems = append(problems, lib.ModelProblem{CellId: cell.Id, Code: lib.ProblemMissingOperatorId, Message: "missing operator id"})
+		}
+	}
+
+	for _, cell := range model.Cells {
+		if cell.Id == "" || !isLink(cell) {
+			continue
+		}
+		if cell.Source == nil {
+			problems = append(problems, lib.ModelProblem{CellId: cell.Id, Code: lib.ProblemMissingSource, Message: "link without source"})
+		} else if p, ok := validateLinkEnd(nodes, cell.Id, *cell.Source, "source", func(node lib.Cell) []string { return node.OutPorts }); !ok {
+			problems = append(problems, p)
+		}
+		if cell.Target == nil {
+			problems = append(problems, lib.ModelProblem{CellId: cell.Id, Code: lib.ProblemMissingTarget, Message: "link without target"})
+		} else if p, ok := validateLinkEnd(nodes, cell.Id, *cell.Target, "target", func(node lib.Cell) []string { return node.InPorts }); !ok {
+			problems = append(problems, p)
+		}
+	}
+
+	return newModelError(MessageInvalidModel, problems)
+}
+
+func newModelError(msg string, problems []lib.ModelProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return lib.NewModelError(msg, problems)
+}
+
+// validateLinkEnd checks that a link end references an existing port of an existing node, problems are reported for the link.
+func validateLinkEnd(nodes map[string]lib.Cell, linkId string, end lib.CellLink, name string, ports func(node lib.Cell) []string) (lib.ModelProblem, bool) {
+	node, ok := nodes[end.Id]
+	if !ok {
+		return lib.ModelProblem{CellId: linkId, Code: lib.ProblemUnknownNode, Message: fmt.Sprintf("%s references unknown node '%s'", name, end.Id)}, false
+	}
+	if !slices.Contains(ports(node), end.Port) {
+		return lib.ModelProblem{CellId: linkId, Port: end.Port, Code: lib.ProblemUnknownPort,
+			Message: fmt.Sprintf("%s references unknown port '%s' of node '%s'", name, end.Port, end.Id)}, false
+	}
+	return lib.ModelProblem{}, true
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-flow-repo-v2/lib"
+)
+
+func testNode(id string, inPorts ...string) lib.Cell {
+	operatorId := "operator-" + id
+	return lib.Cell{Id: id, Type: NodeElementType, OperatorId: &operatorId, InPorts: inPorts, OutPorts: []string{"out"}}
+}
+
+func testLink(id, source, target, port string) lib.Cell {
+	return lib.Cell{Id: id, Type: "link", Source: &lib.CellLink{Id: source, Port: "out"}, Target: &lib.CellLink{Id: target, Port: port}}
+}
+
+func TestValidateModel(t *testing.T) {
+	model := lib.Model{Cells: []lib.Cell{
+		testNode("a"),
+		testNode("b", "in"),
+		testNode("b"),
+		{Type: NodeElementType},
+		testLink("ab", "a", "b", "other"),
+		testLink("ax", "a", "x", "in"),
+		{Id: "half", Source: &lib.CellLink{Id: "a", Port: "out"}},
+	}}
+	err := validateModel(model)
+	var me *lib.ModelError
+	if !errors.As(err, &me) || !errors.As(err, new(*lib.InputError)) {
+		t.Fatalf("expected model error, got %v", err)
+	}
+	expected := []lib.ModelProblem{
+		{CellId: "b", Code: lib.ProblemDuplicateId, Message: "duplicate id"},
+		{CellId: "", Code: lib.ProblemMissingId, Message: "missing id"},
+		{CellId: "ab", Port: "other", Code: lib.ProblemUnknownPort, Message: "target references unknown port 'other' of node 'b'"},
+		{CellId: "ax", Code: lib.ProblemUnknownNode, Message: "target references unknown node 'x'"},
+		{CellId: "half", Code: lib.ProblemMissingTarget, Message: "link without target"},
+	}
+	if !slices.Equal(me.Problems, expected) {
+		t.Fatalf("expected problems %+v, got %+v", expected, me.Problems)
+	}
+
+	model.Cells = []lib.Cell{testNode("a"), testNode("b", "in"), testLink("ab", "a", "b", "in")}
+	err = validateModel(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
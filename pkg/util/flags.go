@@ -21,13 +21,19 @@ import (
 )
 
 type flags struct {
-	ConfPath string
+	ConfPath      string
+	EnsureIndexes bool
+	Migrate       bool
+	MigrateDryRun bool
 }
 
 var Flags flags
 
 func ParseFlags() {
 	flag.StringVar(&Flags.ConfPath, "config", "", "path to config JSON file")
+	flag.BoolVar(&Flags.EnsureIndexes, "ensure-indexes", false, "create missing database indexes and exit")
+	flag.BoolVar(&Flags.Migrate, "migrate", false, "apply pending database migrations and exit")
+	flag.BoolVar(&Flags.MigrateDryRun, "migrate-dry-run", false, "log pending database migrations without applying them and exit")
 	flag.Parse()
 	return
 }